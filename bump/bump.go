@@ -6,51 +6,130 @@ import (
 	"github.com/Masterminds/semver/v3"
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	log "github.com/sirupsen/logrus"
 	"github.com/sky-uk/vergo/git"
 	"github.com/sky-uk/vergo/release"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
 var (
-	ErrUnknownIncrementor = errors.New("unknown incrementor")
+	ErrUnknownIncrementor    = errors.New("unknown incrementor")
+	ErrInvalidPreRelease     = errors.New("invalid pre-release")
+	ErrNoPreReleaseCounter   = errors.New("pre-release has no counter")
+	ErrNotPreRelease         = errors.New("not a pre-release")
+	ErrInvalidInitialVersion = errors.New("invalid initial version")
 )
 
-func NextVersion(increment string, version semver.Version) (incrementedVersion semver.Version, err error) {
+const (
+	DefaultPreReleaseIdentifier = "alpha1"
+)
+
+var legacyPreRelease = regexp.MustCompile(`^(.*[a-zA-Z-])(\d+)$`)
+
+// NextVersion increments the version, preReleaseIdentifier is used when a pre-release is started and
+// defaults to DefaultPreReleaseIdentifier when empty.
+// prepatch, preminor and premajor increment the version and start a pre-release e.g. 1.2.3 -> 1.3.0-rc.1,
+// release (alias promote) drops the pre-release e.g. 1.3.0-rc.4 -> 1.3.0.
+func NextVersion(increment string, version semver.Version, preReleaseIdentifier string) (incrementedVersion semver.Version, err error) {
 	switch strings.ToLower(increment) {
 	case "prerelease":
-		incrementedVersion = NextPreRelease(version)
+		incrementedVersion, err = NextPreRelease(version, preReleaseIdentifier)
 	case "patch":
 		incrementedVersion = version.IncPatch()
 	case "minor":
 		incrementedVersion = version.IncMinor()
 	case "major":
 		incrementedVersion = version.IncMajor()
+	case "prepatch":
+		incrementedVersion, err = setPreRelease(finalVersion(version).IncPatch(), StartPreRelease(preReleaseIdentifier))
+	case "preminor":
+		incrementedVersion, err = setPreRelease(version.IncMinor(), StartPreRelease(preReleaseIdentifier))
+	case "premajor":
+		incrementedVersion, err = setPreRelease(version.IncMajor(), StartPreRelease(preReleaseIdentifier))
+	case "release", "promote":
+		if version.Prerelease() == "" {
+			return semver.Version{}, fmt.Errorf("%w : %s", ErrNotPreRelease, version.String())
+		}
+		incrementedVersion = finalVersion(version)
 	default:
 		err = fmt.Errorf("%w : %s", ErrUnknownIncrementor, increment)
 	}
 	return
 }
 
-func NextPreRelease(version semver.Version) (incrementedVersion semver.Version) {
-	var currentPreRelease = version.Prerelease()
+// NextPreRelease increments the last numeric identifier of the pre-release following SemVer 2.0
+// e.g. rc.1 -> rc.2, the legacy form alpha1 -> alpha2 is still supported.
+// A pre-release without a counter gets one, beta -> beta.1, and a release starts one on the next patch
+// with StartPreRelease(identifier), 1.2.0 -> 1.2.1-rc.1, as a pre-release sorts below its release.
+func NextPreRelease(version semver.Version, identifier string) (semver.Version, error) {
+	currentPreRelease := version.Prerelease()
 	if currentPreRelease == "" {
-		incrementedVersion, _ = version.SetPrerelease("alpha1")
-	} else {
-		suffix, num := ParsePreRelease(currentPreRelease)
-		incrementedVersion, _ = version.SetPrerelease(fmt.Sprintf(suffix+"%d", num+1))
+		return setPreRelease(version.IncPatch(), StartPreRelease(identifier))
 	}
-	return
+	prefix, number, err := ParsePreRelease(currentPreRelease)
+	switch {
+	case errors.Is(err, ErrNoPreReleaseCounter):
+		return setPreRelease(version, currentPreRelease+".1")
+	case err != nil:
+		return semver.Version{}, err
+	}
+	if number == math.MaxInt {
+		return semver.Version{}, fmt.Errorf("%w : %s counter overflow", ErrInvalidPreRelease, currentPreRelease)
+	}
+	return setPreRelease(version, prefix+strconv.Itoa(number+1))
 }
 
-func ParsePreRelease(preRelease string) (suffix string, number int) {
-	re := regexp.MustCompile(`([a-zA-Z]+)(\d+)`)
-	match := re.FindStringSubmatch(preRelease)
-	suffix = match[1]
-	number, _ = strconv.Atoi(match[2])
-	return
+// StartPreRelease returns the first pre-release for the identifier, rc -> rc.1 while identifiers already
+// ending with a counter e.g. rc.0 or alpha1 are used as they are.
+func StartPreRelease(identifier string) string {
+	switch {
+	case identifier == "":
+		return DefaultPreReleaseIdentifier
+	case identifier[len(identifier)-1] >= '0' && identifier[len(identifier)-1] <= '9':
+		return identifier
+	default:
+		return identifier + ".1"
+	}
+}
+
+// ParsePreRelease splits the pre-release into everything before its counter and the counter,
+// rc.1 -> (rc., 1) and alpha1 -> (alpha, 1). ErrNoPreReleaseCounter is returned when there is no counter.
+func ParsePreRelease(preRelease string) (prefix string, number int, err error) {
+	if preRelease == "" {
+		return "", 0, fmt.Errorf("%w : empty", ErrInvalidPreRelease)
+	}
+	identifiers := strings.Split(preRelease, ".")
+	last := identifiers[len(identifiers)-1]
+	prefix = strings.TrimSuffix(preRelease, last)
+	if match := legacyPreRelease.FindStringSubmatch(last); match != nil {
+		prefix, last = prefix+match[1], match[2]
+	} else if strings.Trim(last, "0123456789") != "" || last == "" {
+		return "", 0, fmt.Errorf("%w : %s", ErrNoPreReleaseCounter, preRelease)
+	}
+	number, err = strconv.Atoi(last)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w : %s", ErrInvalidPreRelease, preRelease)
+	}
+	return prefix, number, nil
+}
+
+// finalVersion drops the pre-release and metadata
+func finalVersion(version semver.Version) semver.Version {
+	version, _ = version.SetPrerelease("")
+	version, _ = version.SetMetadata("")
+	return version
+}
+
+func setPreRelease(version semver.Version, preRelease string) (semver.Version, error) {
+	incrementedVersion, err := version.SetPrerelease(preRelease)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("%w : %s", ErrInvalidPreRelease, preRelease)
+	}
+	return incrementedVersion, nil
 }
 
 const (
@@ -63,6 +142,52 @@ type Options struct {
 	VersionedBranches []string
 	DryRun            bool
 	NearestRelease    bool
+	// PreReleaseIdentifier starts new pre-releases, DefaultPreReleaseIdentifier when empty
+	PreReleaseIdentifier string
+	// AllowLower allows Set to tag a version which is not greater than the latest release
+	AllowLower bool
+	// InitialVersion is the first release when there is no tag, defaults to 0.1.0
+	InitialVersion string
+	// InitialIncrement computes the first release by applying the increment to 0.0.0, InitialVersion is ignored
+	InitialIncrement bool
+	// VersionLines are the branch patterns of maintenance branches e.g. release/{major}.{minor},
+	// bumps on them stay inside the version line of the branch
+	VersionLines []string
+	// Channels map branch patterns to pre-release identifiers e.g. develop=beta or feature/*={branch},
+	// pre-release increments on a channel branch create the channel pre-release without requiring a versioned branch
+	Channels []string
+	// VersionGroup are the tag prefixes sharing the version of TagPrefix, bumping any of them tags every member
+	// with the next version of the highest release across the group. Channels do not apply to version groups
+	VersionGroup []string
+	// PreTag is called with the new version before HEAD is tagged e.g. to commit release files,
+	// the tags are created on HEAD after the call. It is not called when HEAD is already released
+	PreTag func(version semver.Version) error
+	// TagMessage returns the message of the new version, the tags are annotated when it is set and
+	// the message is not blank, lightweight otherwise
+	TagMessage func(version semver.Version) (string, error)
+	// Tagger is the identity of annotated tags
+	Tagger *object.Signature
+	// Signer signs the annotated tags
+	Signer git.TagSigner
+}
+
+// InitialVersion returns the first release of a prefix without tags.
+func InitialVersion(increment string, options Options) (*semver.Version, error) {
+	if options.InitialIncrement {
+		version, err := NextVersion(increment, *semver.MustParse("0.0.0"), options.PreReleaseIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		return &version, nil
+	}
+	if options.InitialVersion == "" {
+		return semver.NewVersion(firstVersion)
+	}
+	version, err := semver.NewVersion(options.InitialVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidInitialVersion, options.InitialVersion)
+	}
+	return version, nil
 }
 
 type Func func(repo *gogit.Repository, increment string, options Options) (*semver.Version, error)
@@ -72,27 +197,41 @@ func Bump(repo *gogit.Repository, increment string, options Options) (*semver.Ve
 	if err != nil {
 		return nil, err
 	}
-	if err := release.ValidateHEAD(repo, options.Remote, options.VersionedBranches); err != nil {
-		return nil, err
+	if len(options.VersionGroup) > 0 {
+		line, err := validateHEAD(repo, options)
+		if err != nil {
+			return nil, err
+		}
+		return bumpGroup(repo, increment, line, options)
 	}
-
-	var latest git.SemverRef
-	if options.NearestRelease {
-		latest, err = git.NearestTag(repo, options.TagPrefix)
-	} else {
-		latest, err = git.LatestRef(repo, options.TagPrefix)
+	if IsPreReleaseIncrement(increment) {
+		identifier, err := release.CurrentChannel(repo, options.Remote, options.Channels)
+		if err != nil {
+			return nil, err
+		}
+		if identifier != "" {
+			return bumpChannel(repo, increment, identifier, options)
+		}
+	}
+	line, err := validateHEAD(repo, options)
+	if err != nil {
+		return nil, err
 	}
 
+	latest, err := latestRelease(repo, line, "", options)
 	if errors.Is(err, git.ErrNoTagFound) {
-		newVersion, err := semver.NewVersion(firstVersion)
+		newVersion, err := firstRelease(repo, increment, line, options)
 		if err != nil {
 			return nil, err
 		}
-		if err := git.CreateTag(repo, newVersion.String(), options.TagPrefix, options.DryRun); err != nil {
+		if err := createTags(repo, newVersion, options); err != nil {
 			log.WithError(err).Errorln("Failed to create tag", options.TagPrefix, newVersion.String())
 			return nil, err
 		}
-		return newVersion, nil
+		return &newVersion, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 	switch tagObject, err := repo.TagObject(latest.Ref.Hash()); {
 	case err == nil && tagObject.Target == head.Hash() && tagObject.TargetType == plumbing.CommitObject:
@@ -106,12 +245,126 @@ func Bump(repo *gogit.Repository, increment string, options Options) (*semver.Ve
 	case err != plumbing.ErrObjectNotFound:
 		return nil, err
 	}
-	newVersion, err := NextVersion(increment, *latest.Version)
+	newVersion, err := nextVersion(repo, increment, latest, line, options)
 	if err != nil {
 		return nil, err
 	}
-	if err := git.CreateTag(repo, newVersion.String(), options.TagPrefix, options.DryRun); err != nil {
+	if err := createTags(repo, newVersion, options); err != nil {
 		return nil, err
 	}
 	return &newVersion, nil
 }
+
+// LatestRelease returns the release Bump bases the next version on, on a maintenance branch the latest release
+// of its version line.
+func LatestRelease(repo *gogit.Repository, options Options) (git.SemverRef, error) {
+	line, err := release.CurrentVersionLine(repo, options.Remote, options.VersionLines)
+	if err != nil {
+		return git.EmptyRef, err
+	}
+	return latestRelease(repo, line, "", options)
+}
+
+// latestRelease returns the release the next version is based on, the latest of the version line on a maintenance
+// branch, the nearest or the highest release otherwise. identifier is the pre-release channel of HEAD, empty on
+// mainline, pre-releases of other channels are ignored.
+func latestRelease(repo *gogit.Repository, line *release.VersionLine, identifier string, options Options) (git.SemverRef, error) {
+	match := channelMatch(identifier, options)
+	switch {
+	case line != nil:
+		latest, err := git.LatestRefMatching(repo, options.TagPrefix, func(version *semver.Version) bool {
+			return line.Contains(version) && (match == nil || match(version))
+		})
+		if errors.Is(err, git.ErrNoTagFound) {
+			return latest, fmt.Errorf("%w : %s", git.ErrNoTagFound, line.String())
+		}
+		return latest, err
+	case options.NearestRelease:
+		return git.NearestTagMatching(repo, options.TagPrefix, match)
+	case match != nil:
+		return git.LatestRefMatching(repo, options.TagPrefix, match)
+	default:
+		return git.LatestRef(repo, options.TagPrefix)
+	}
+}
+
+// nextVersion increments the latest release within the version line or up to the next version marker.
+// The marker only applies to increments computing a new core version, not to promoting or continuing a pre-release.
+func nextVersion(repo *gogit.Repository, increment string, latest git.SemverRef, line *release.VersionLine,
+	options Options) (semver.Version, error) {
+	newVersion, err := NextVersion(increment, *latest.Version, options.PreReleaseIdentifier)
+	if err != nil {
+		return semver.Version{}, err
+	}
+	if line == nil {
+		if !newCore(increment, *latest.Version) {
+			return newVersion, nil
+		}
+		return git.WithMarker(repo, options.TagPrefix, newVersion)
+	}
+	if !line.Contains(&newVersion) {
+		return semver.Version{}, fmt.Errorf("%w : %s %s of %s is outside %s of branch %s", release.ErrOutsideVersionLine,
+			increment, newVersion.String(), latest.Version.String(), line.String(), line.Branch)
+	}
+	return newVersion, nil
+}
+
+// newCore returns true when the increment of the version computes a new version without pre-release.
+func newCore(increment string, version semver.Version) bool {
+	switch strings.ToLower(increment) {
+	case "release", "promote":
+		return false
+	case "prerelease":
+		return version.Prerelease() == ""
+	default:
+		return true
+	}
+}
+
+// validateHEAD checks HEAD is on a versioned branch and returns its version line.
+func validateHEAD(repo *gogit.Repository, options Options) (*release.VersionLine, error) {
+	if err := release.ValidateHEAD(repo, options.Remote, options.VersionedBranches); err != nil {
+		return nil, err
+	}
+	return release.CurrentVersionLine(repo, options.Remote, options.VersionLines)
+}
+
+// firstRelease returns the first release of the prefix, on a maintenance branch the first release of its
+// version line e.g. 1.4.0 or 1.4.0-rc.1 on release/1.4. Next version markers do not apply to version lines.
+func firstRelease(repo *gogit.Repository, increment string, line *release.VersionLine, options Options) (semver.Version, error) {
+	if line != nil {
+		if IsPreReleaseIncrement(increment) {
+			return setPreRelease(*line.Initial(), StartPreRelease(options.PreReleaseIdentifier))
+		}
+		return *line.Initial(), nil
+	}
+	initialVersion, err := InitialVersion(increment, options)
+	if err != nil {
+		return semver.Version{}, err
+	}
+	return git.WithMarker(repo, options.TagPrefix, *initialVersion)
+}
+
+// createTags checks every member of the version group is free, calls options.PreTag and tags HEAD for every member.
+func createTags(repo *gogit.Repository, version semver.Version, options Options) error {
+	if err := git.CheckTagsAvailable(repo, version.String(), groupPrefixes(options)); err != nil {
+		return err
+	}
+	if options.PreTag != nil {
+		if err := options.PreTag(version); err != nil {
+			return err
+		}
+	}
+	message := ""
+	if options.TagMessage != nil {
+		var err error
+		if message, err = options.TagMessage(version); err != nil {
+			return err
+		}
+	}
+	return git.CreateTags(repo, version.String(), groupPrefixes(options), git.TagOptions{
+		Message: message,
+		Tagger:  options.Tagger,
+		Signer:  options.Signer,
+	}, options.DryRun)
+}
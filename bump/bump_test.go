@@ -11,6 +11,7 @@ import (
 	. "github.com/sky-uk/vergo/bump"
 	. "github.com/sky-uk/vergo/git"
 	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/sky-uk/vergo/release"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -19,9 +20,10 @@ import (
 const firstVersion = "0.1.0"
 
 var (
-	prefixes   = []string{"", "app", "application", "app/v"}
-	increments = []string{"prerelease", "patch", "minor", "major"}
-	mainBranch = []string{"master", "main"}
+	prefixes     = []string{"", "app", "application", "app/v"}
+	increments   = []string{"prerelease", "patch", "minor", "major"}
+	mainBranch   = []string{"master", "main"}
+	versionLines = []string{"release/{major}.{minor}", "support/{major}.x"}
 )
 
 //nolint:scopelint,paralleltest
@@ -34,7 +36,7 @@ func TestShouldIncrementVersion(t *testing.T) {
 		{
 			increment: "prerelease",
 			pre:       NewVersionT(t, "v0.1.0"),
-			post:      NewVersionT(t, "v0.1.0-alpha1"),
+			post:      NewVersionT(t, "v0.1.1-alpha1"),
 		},
 		{
 			increment: "prerelease",
@@ -56,10 +58,35 @@ func TestShouldIncrementVersion(t *testing.T) {
 			pre:       NewVersionT(t, "v0.1.0"),
 			post:      NewVersionT(t, "v1.0.0"),
 		},
+		{
+			increment: "prepatch",
+			pre:       NewVersionT(t, "v1.2.3-rc.1"),
+			post:      NewVersionT(t, "v1.2.4-alpha1"),
+		},
+		{
+			increment: "preminor",
+			pre:       NewVersionT(t, "v1.2.3"),
+			post:      NewVersionT(t, "v1.3.0-alpha1"),
+		},
+		{
+			increment: "premajor",
+			pre:       NewVersionT(t, "v1.2.3"),
+			post:      NewVersionT(t, "v2.0.0-alpha1"),
+		},
+		{
+			increment: "release",
+			pre:       NewVersionT(t, "v1.3.0-rc.4"),
+			post:      NewVersionT(t, "v1.3.0"),
+		},
+		{
+			increment: "promote",
+			pre:       NewVersionT(t, "v1.3.0-rc.4+abc"),
+			post:      NewVersionT(t, "v1.3.0"),
+		},
 	}
 	for _, version := range versions {
 		t.Run(version.increment, func(t *testing.T) {
-			actual, err := NextVersion(version.increment, *version.pre)
+			actual, err := NextVersion(version.increment, *version.pre, "")
 			assert.Nil(t, err)
 			assert.Equal(t, actual, *version.post)
 		})
@@ -275,7 +302,7 @@ func TestBumpAllIncrements(t *testing.T) {
 			increment:         "prerelease",
 			versionedBranches: mainBranch,
 			pre:               NewVersionT(t, "0.1.0"),
-			post:              NewVersionT(t, "0.1.0-alpha1"),
+			post:              NewVersionT(t, "0.1.1-alpha1"),
 		},
 		{
 			increment:         "prerelease",
@@ -315,3 +342,481 @@ func TestBumpAllIncrements(t *testing.T) {
 		}
 	}
 }
+
+//nolint:scopelint,paralleltest
+func TestShouldIncrementDottedPreRelease(t *testing.T) {
+	versions := []struct {
+		identifier string
+		pre        string
+		post       string
+	}{
+		{identifier: "", pre: "1.2.0", post: "1.2.1-alpha1"},
+		{identifier: "rc", pre: "1.2.0", post: "1.2.1-rc.1"},
+		{identifier: "rc.0", pre: "1.2.0", post: "1.2.1-rc.0"},
+		{identifier: "", pre: "1.2.0-rc.1", post: "1.2.0-rc.2"},
+		{identifier: "", pre: "1.2.0-rc.9", post: "1.2.0-rc.10"},
+		{identifier: "", pre: "1.2.0-beta", post: "1.2.0-beta.1"},
+		{identifier: "", pre: "1.2.0-SNAPSHOT", post: "1.2.0-SNAPSHOT.1"},
+		{identifier: "", pre: "1.2.0-beta.2.x", post: "1.2.0-beta.2.x.1"},
+		{identifier: "", pre: "1.2.0-alpha5", post: "1.2.0-alpha6"},
+		{identifier: "", pre: "1.2.0-1", post: "1.2.0-2"},
+	}
+	for _, version := range versions {
+		t.Run(version.pre+"-"+version.identifier, func(t *testing.T) {
+			actual, err := NextPreRelease(*NewVersionT(t, version.pre), version.identifier)
+			assert.Nil(t, err)
+			assert.Equal(t, version.post, actual.String())
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestBumpShouldContinuePreReleasesOfARelease(t *testing.T) {
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			options := Options{TagPrefix: prefix, VersionedBranches: mainBranch, PreReleaseIdentifier: "rc"}
+			tag, err := Bump(r.Repo, "minor", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.0", tag.String())
+			for _, expected := range []string{"0.1.1-rc.1", "0.1.1-rc.2", "0.1.1-rc.3"} {
+				r.DoCommit("foo")
+				tag, err = Bump(r.Repo, "prerelease", options)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, tag.String())
+			}
+			r.DoCommit("bar")
+			tag, err = Bump(r.Repo, "release", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.1", tag.String())
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldStartPreReleaseWithIdentifier(t *testing.T) {
+	actual, err := NextVersion("preminor", *NewVersionT(t, "1.2.3"), "rc")
+	assert.Nil(t, err)
+	assert.Equal(t, "1.3.0-rc.1", actual.String())
+
+	_, err = NextVersion("release", *NewVersionT(t, "1.2.3"), "rc")
+	assert.ErrorIs(t, err, ErrNotPreRelease)
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldFailToIncrementInvalidPreRelease(t *testing.T) {
+	_, err := NextPreRelease(*NewVersionT(t, "1.2.0"), "rc_1")
+	assert.ErrorIs(t, err, ErrInvalidPreRelease)
+
+	_, err = NextPreRelease(*NewVersionT(t, "1.2.0-rc.99999999999999999999"), "")
+	assert.ErrorIs(t, err, ErrInvalidPreRelease)
+
+	_, _, err = ParsePreRelease("SNAPSHOT")
+	assert.ErrorIs(t, err, ErrNoPreReleaseCounter)
+}
+
+//nolint:scopelint,paralleltest
+func TestSetShouldTagExplicitVersion(t *testing.T) {
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			r.CreateTag(prefix+"1.2.0", r.Head().Hash())
+			r.DoCommit("bar")
+
+			_, err := Set(r.Repo, NewVersionT(t, "1.2.0"), Options{TagPrefix: prefix, VersionedBranches: mainBranch})
+			assert.ErrorIs(t, err, gogit.ErrTagExists)
+
+			_, err = Set(r.Repo, NewVersionT(t, "1.1.0"), Options{TagPrefix: prefix, VersionedBranches: mainBranch})
+			assert.ErrorIs(t, err, ErrVersionNotGreater)
+
+			version, err := Set(r.Repo, NewVersionT(t, "2024.1.0"), Options{TagPrefix: prefix, VersionedBranches: mainBranch})
+			assert.Nil(t, err)
+			assert.Equal(t, NewVersionT(t, "2024.1.0"), version)
+
+			version, err = Set(r.Repo, NewVersionT(t, "2024.1.0"), Options{TagPrefix: prefix, VersionedBranches: mainBranch})
+			assert.Nil(t, err)
+			assert.Equal(t, NewVersionT(t, "2024.1.0"), version)
+
+			r.DoCommit("baz")
+			version, err = Set(r.Repo, NewVersionT(t, "1.3.0"), Options{TagPrefix: prefix, VersionedBranches: mainBranch, AllowLower: true})
+			assert.Nil(t, err)
+			assert.Equal(t, NewVersionT(t, "1.3.0"), version)
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestSetShouldFailWhenNotOnMainBranch(t *testing.T) {
+	r := NewTestRepo(t)
+	err := r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("apple"), Create: true})
+	assert.Nil(t, err)
+	_, err = Set(r.Repo, NewVersionT(t, "1.0.0"), Options{VersionedBranches: mainBranch})
+	assert.Regexp(t, "branch apple is not in versioned branches list: master, main", err)
+}
+
+//nolint:scopelint,paralleltest
+func TestBumpShouldCreateConfiguredFirstTag(t *testing.T) {
+	firstVersions := []struct {
+		increment string
+		options   Options
+		version   string
+	}{
+		{increment: "patch", options: Options{InitialVersion: "1.0.0"}, version: "1.0.0"},
+		{increment: "major", options: Options{InitialIncrement: true}, version: "1.0.0"},
+		{increment: "minor", options: Options{InitialIncrement: true, InitialVersion: "5.0.0"}, version: "0.1.0"},
+		{increment: "patch", options: Options{InitialIncrement: true}, version: "0.0.1"},
+	}
+	for _, prefix := range prefixes {
+		for _, firstVersion := range firstVersions {
+			t.Run(prefix+"-"+firstVersion.increment+"-"+firstVersion.version, func(t *testing.T) {
+				r := NewTestRepo(t)
+				options := firstVersion.options
+				options.TagPrefix = prefix
+				options.VersionedBranches = mainBranch
+				newVersion, err := Bump(r.Repo, firstVersion.increment, options)
+				assert.Nil(t, err)
+				assert.Equal(t, firstVersion.version, newVersion.String())
+			})
+		}
+	}
+}
+
+func TestBumpShouldFailWithInvalidInitialVersion(t *testing.T) {
+	r := NewTestRepo(t)
+	_, err := Bump(r.Repo, "patch", Options{VersionedBranches: mainBranch, InitialVersion: "one"})
+	assert.ErrorIs(t, err, ErrInvalidInitialVersion)
+}
+
+//nolint:scopelint,paralleltest
+func TestBumpShouldHonourNextVersionMarker(t *testing.T) {
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			_, err := MarkNext(r.Repo, NewVersionT(t, "1.0.0"), Options{TagPrefix: prefix})
+			assert.Nil(t, err)
+			_, err = MarkNext(r.Repo, NewVersionT(t, "1.0.0-alpha"), Options{TagPrefix: prefix})
+			assert.ErrorIs(t, err, ErrInvalidMarker)
+			{
+				tag, err := Bump(r.Repo, "minor", Options{TagPrefix: prefix, VersionedBranches: mainBranch})
+				assert.Nil(t, err)
+				assert.Equal(t, "1.0.0", tag.String())
+			}
+
+			r.DoCommit("foo")
+			_, err = MarkNext(r.Repo, NewVersionT(t, "1.0.0"), Options{TagPrefix: prefix})
+			assert.ErrorIs(t, err, ErrVersionNotGreater)
+			_, err = MarkNext(r.Repo, NewVersionT(t, "2.0.0"), Options{TagPrefix: prefix})
+			assert.Nil(t, err)
+			r.DoCommit("bar")
+			{
+				tag, err := Bump(r.Repo, "prerelease", Options{TagPrefix: prefix, VersionedBranches: mainBranch, PreReleaseIdentifier: "rc"})
+				assert.Nil(t, err)
+				assert.Equal(t, "2.0.0-rc.1", tag.String())
+			}
+
+			r.DoCommit("baz")
+			{
+				tag, err := Bump(r.Repo, "major", Options{TagPrefix: prefix, VersionedBranches: mainBranch})
+				assert.Nil(t, err)
+				assert.Equal(t, "3.0.0", tag.String())
+			}
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestNextVersionMarkerShouldOnlyMoveNewVersions(t *testing.T) {
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			options := Options{TagPrefix: prefix, VersionedBranches: mainBranch, PreReleaseIdentifier: "rc", Channels: []string{"develop=beta"}}
+			r.CreateTag(prefix+"1.3.0-rc.1", r.Head().Hash())
+			_, err := MarkNext(r.Repo, NewVersionT(t, "2.0.0"), options)
+			assert.Nil(t, err)
+			r.DoCommit("foo")
+			tag, err := Bump(r.Repo, "prerelease", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "1.3.0-rc.2", tag.String(), "a pre-release is continued")
+			r.DoCommit("bar")
+			tag, err = Bump(r.Repo, "promote", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "1.3.0", tag.String(), "a pre-release is promoted")
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("develop"), Create: true})
+			assert.Nil(t, err)
+			for _, expected := range []string{"2.0.0-beta.1", "2.0.0-beta.2"} {
+				r.DoCommit(expected)
+				tag, err = Bump(r.Repo, "prerelease", options)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, tag.String(), "channels honour the marker")
+			}
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestMarkNextShouldCompareWithTheBumpedRelease(t *testing.T) {
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			options := Options{TagPrefix: prefix, VersionedBranches: mainBranch}
+			_, err := Bump(r.Repo, "major", options)
+			assert.Nil(t, err)
+			releaseCommit := r.Head().Hash()
+			r.DoCommit("foo")
+			_, err = Bump(r.Repo, "major", options)
+			assert.Nil(t, err)
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Hash: releaseCommit, Branch: plumbing.NewBranchReferenceName("release/0.1"), Create: true})
+			assert.Nil(t, err)
+			_, err = MarkNext(r.Repo, NewVersionT(t, "0.5.0"), options)
+			assert.ErrorIs(t, err, ErrVersionNotGreater)
+			_, err = MarkNext(r.Repo, NewVersionT(t, "0.5.0"), Options{TagPrefix: prefix, NearestRelease: true})
+			assert.Nil(t, err)
+			_, err = MarkNext(r.Repo, NewVersionT(t, "0.1.1"), Options{TagPrefix: prefix, VersionLines: versionLines})
+			assert.Nil(t, err)
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestBumpShouldStayInsideVersionLine(t *testing.T) {
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			options := Options{TagPrefix: prefix, VersionedBranches: []string{"master", "release/*", "support/*"}, VersionLines: versionLines}
+			tag, err := Bump(r.Repo, "minor", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.0", tag.String())
+			r.DoCommit("foo")
+			tag, err = Bump(r.Repo, "major", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "1.0.0", tag.String())
+			releaseCommit := r.Head().Hash()
+			r.DoCommit("bar")
+			tag, err = Bump(r.Repo, "major", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "2.0.0", tag.String())
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Hash: releaseCommit, Branch: plumbing.NewBranchReferenceName("release/1.0"), Create: true})
+			assert.Nil(t, err)
+			r.DoCommit("baz")
+			_, err = Bump(r.Repo, "patch", Options{TagPrefix: prefix, VersionedBranches: mainBranch, VersionLines: versionLines})
+			assert.NotNil(t, err)
+			tag, err = Bump(r.Repo, "patch", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "1.0.1", tag.String())
+			r.DoCommit("qux")
+			_, err = Bump(r.Repo, "minor", options)
+			assert.ErrorIs(t, err, release.ErrOutsideVersionLine)
+			_, err = Set(r.Repo, NewVersionT(t, "1.1.0"), options)
+			assert.ErrorIs(t, err, release.ErrOutsideVersionLine)
+			options.PreReleaseIdentifier = "rc"
+			tag, err = Bump(r.Repo, "prepatch", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "1.0.2-rc.1", tag.String())
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("support/1.x"), Create: true})
+			assert.Nil(t, err)
+			r.DoCommit("quux")
+			tag, err = Bump(r.Repo, "minor", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "1.1.0", tag.String())
+			r.DoCommit("corge")
+			_, err = Bump(r.Repo, "major", options)
+			assert.ErrorIs(t, err, release.ErrOutsideVersionLine)
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("release/3.2"), Create: true})
+			assert.Nil(t, err)
+			tag, err = Bump(r.Repo, "patch", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "3.2.0", tag.String())
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestBumpShouldCreateChannelPreReleases(t *testing.T) {
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			options := Options{TagPrefix: prefix, VersionedBranches: mainBranch, Channels: []string{"develop=beta", "feature/*={branch}"}}
+			tag, err := Bump(r.Repo, "minor", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.0", tag.String())
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("develop"), Create: true})
+			assert.Nil(t, err)
+			tag, err = Bump(r.Repo, "prerelease", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.0", tag.String(), "HEAD is already released")
+			for _, expected := range []string{"0.1.1-beta.1", "0.1.1-beta.2"} {
+				r.DoCommit("foo")
+				tag, err = Bump(r.Repo, "prerelease", options)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, tag.String())
+			}
+			tag, err = Bump(r.Repo, "prerelease", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.1-beta.2", tag.String(), "HEAD is already tagged")
+			_, err = Bump(r.Repo, "patch", options)
+			assert.Regexp(t, "branch develop is not in versioned branches list", err)
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature/login_flow"), Create: true})
+			assert.Nil(t, err)
+			r.DoCommit("bar")
+			tag, err = Bump(r.Repo, "prerelease", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.1-login-flow.1", tag.String())
+			r.DoCommit("baz")
+			tag, err = Bump(r.Repo, "premajor", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "1.0.0-login-flow.1", tag.String())
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")})
+			assert.Nil(t, err)
+			r.DoCommit("qux")
+			tag, err = Bump(r.Repo, "patch", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.1", tag.String(), "channel pre-releases are not mainline releases")
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestBumpShouldCreateChannelPreReleasesInsideVersionLine(t *testing.T) {
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			options := Options{TagPrefix: prefix, VersionedBranches: mainBranch, VersionLines: versionLines, Channels: []string{"release/*=rc"}}
+			tag, err := Bump(r.Repo, "minor", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.0", tag.String())
+			releaseCommit := r.Head().Hash()
+			r.DoCommit("foo")
+			tag, err = Bump(r.Repo, "major", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "1.0.0", tag.String())
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Hash: releaseCommit, Branch: plumbing.NewBranchReferenceName("release/0.1"), Create: true})
+			assert.Nil(t, err)
+			r.DoCommit("bar")
+			tag, err = Bump(r.Repo, "prepatch", options)
+			assert.Nil(t, err)
+			assert.Equal(t, "0.1.1-rc.1", tag.String())
+			r.DoCommit("baz")
+			_, err = Bump(r.Repo, "premajor", options)
+			assert.ErrorIs(t, err, release.ErrOutsideVersionLine)
+		})
+	}
+}
+
+func TestPlanShouldPropagateReleasesToDependants(t *testing.T) {
+	projects := []string{"app", "lib-core", "lib-http", "tool"}
+	dependsOn := map[string][]string{
+		"app":      {"lib-http"},
+		"lib-http": {"lib-core"},
+	}
+	{
+		steps, err := Plan(projects, dependsOn, map[string]string{"lib-core": "minor", "tool": "patch"}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []Step{
+			{Project: "lib-core", Increment: "minor"},
+			{Project: "lib-http", Increment: "patch", Cause: "lib-core"},
+			{Project: "app", Increment: "patch", Cause: "lib-http"},
+			{Project: "tool", Increment: "patch"},
+		}, steps)
+		assert.Equal(t, "lib-http patch (depends on lib-core)", steps[1].String())
+	}
+	{
+		steps, err := Plan(projects, dependsOn, map[string]string{"lib-http": "patch", "app": "major"},
+			map[string]string{"app": "minor"})
+		assert.Nil(t, err)
+		assert.Equal(t, []Step{
+			{Project: "lib-http", Increment: "patch"},
+			{Project: "app", Increment: "major"},
+		}, steps)
+	}
+	{
+		steps, err := Plan(projects, dependsOn, map[string]string{"lib-core": "patch", "lib-http": "patch"},
+			map[string]string{"lib-http": "minor"})
+		assert.Nil(t, err)
+		assert.Equal(t, []Step{
+			{Project: "lib-core", Increment: "patch"},
+			{Project: "lib-http", Increment: "minor", Cause: "lib-core"},
+			{Project: "app", Increment: "patch", Cause: "lib-http"},
+		}, steps)
+	}
+}
+
+func TestPlanShouldTakeHighestIncrementOfReleasedDependencies(t *testing.T) {
+	projects := []string{"app", "lib-core", "lib-http", "lib-json"}
+	dependsOn := map[string][]string{"app": {"lib-json", "lib-http", "lib-core"}}
+	steps, err := Plan(projects, dependsOn, map[string]string{"app": "patch", "lib-http": "major", "lib-json": "patch"},
+		map[string]string{"app": "minor"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Step{
+		{Project: "lib-http", Increment: "major"},
+		{Project: "lib-json", Increment: "patch"},
+		{Project: "app", Increment: "minor", Cause: "lib-http"},
+	}, steps)
+}
+
+func TestPlanShouldRejectInvalidDependencies(t *testing.T) {
+	projects := []string{"a", "b", "c"}
+	_, err := Plan(projects, map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}}, nil, nil)
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	assert.Contains(t, err.Error(), "a -> b -> c -> a")
+
+	_, err = Plan(projects, map[string][]string{"a": {"unknown"}}, nil, nil)
+	assert.ErrorIs(t, err, ErrUnknownDependency)
+}
+
+func TestBumpShouldTagEveryMemberOfVersionGroup(t *testing.T) {
+	r := NewTestRepo(t)
+	r.CreateTag("sdk-go-1.2.0", r.Head().Hash())
+	r.CreateTag("sdk-java-1.4.0", r.Head().Hash())
+	r.DoCommit("foo")
+	goOptions := Options{TagPrefix: "sdk-go-", VersionedBranches: mainBranch, VersionGroup: []string{"sdk-go-", "sdk-java-", "sdk-py-"}}
+	tag, err := Bump(r.Repo, "patch", goOptions)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.4.1", tag.String())
+	for _, prefix := range []string{"sdk-go-", "sdk-java-", "sdk-py-"} {
+		latest, err := LatestRef(r.Repo, prefix)
+		assert.Nil(t, err)
+		assert.Equal(t, "1.4.1", latest.Version.String(), prefix)
+	}
+
+	javaOptions := Options{TagPrefix: "sdk-java-", VersionedBranches: mainBranch, VersionGroup: []string{"sdk-go-", "sdk-py-"}}
+	tag, err = Bump(r.Repo, "minor", javaOptions)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.4.1", tag.String(), "HEAD is already tagged")
+
+	// a release of another branch is ignored by the nearest release but its tag exists
+	main := r.Head()
+	err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("hotfix"), Create: true})
+	assert.Nil(t, err)
+	r.DoCommit("bar")
+	r.CreateTag("sdk-py-1.5.0", r.Head().Hash())
+	assert.Nil(t, r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: main.Name()}))
+	r.DoCommit("baz")
+	javaOptions.NearestRelease = true
+	javaOptions.PreTag = func(version semver.Version) error {
+		t.Errorf("PreTag called with %s although a tag exists", version.String())
+		return nil
+	}
+	_, err = Bump(r.Repo, "minor", javaOptions)
+	assert.ErrorIs(t, err, gogit.ErrTagExists)
+	javaOptions.NearestRelease, javaOptions.PreTag = false, nil
+	for _, tag := range []string{"sdk-java-1.5.0", "sdk-go-1.5.0"} {
+		found, err := TagExists(r.Repo, tag)
+		assert.Nil(t, err)
+		assert.False(t, found, tag)
+	}
+
+	version, err := Set(r.Repo, semver.MustParse("2.0.0"), javaOptions)
+	assert.Nil(t, err)
+	assert.Equal(t, "2.0.0", version.String())
+	_, err = Set(r.Repo, semver.MustParse("1.9.0"), goOptions)
+	assert.ErrorIs(t, err, ErrVersionNotGreater)
+}
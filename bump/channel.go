@@ -0,0 +1,146 @@
+package bump
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/release"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// IsPreReleaseIncrement returns true for the increments creating a pre-release.
+func IsPreReleaseIncrement(increment string) bool {
+	switch strings.ToLower(increment) {
+	case "prerelease", "prepatch", "preminor", "premajor":
+		return true
+	default:
+		return false
+	}
+}
+
+// bumpChannel tags HEAD with the next pre-release of the channel identifier e.g. 1.4.0-beta.3, HEAD does not
+// have to be on a versioned branch. prerelease continues the pre-releases of the latest version or starts
+// them on the next patch version of a release like NextPreRelease, prepatch, preminor and premajor increment
+// the latest version.
+// The latest version is the one Bump uses without the pre-releases of other channels, a release of HEAD is
+// returned as it is.
+func bumpChannel(repo *gogit.Repository, increment, identifier string, options Options) (*semver.Version, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	line, err := release.CurrentVersionLine(repo, options.Remote, options.VersionLines)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := latestRelease(repo, line, identifier, options)
+	switch {
+	case errors.Is(err, git.ErrNoTagFound):
+	case err != nil:
+		return nil, err
+	default:
+		commit, err := git.TagCommit(repo, latest.Ref)
+		if err != nil {
+			return nil, err
+		}
+		if commit == head.Hash() {
+			return latest.Version, nil
+		}
+	}
+	core, err := channelCore(repo, increment, identifier, latest.Version, line, options)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := git.ListRefs(repo, options.TagPrefix, git.DESC, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	counter := 0
+	for _, ref := range refs {
+		if refCore := finalVersion(*ref.Version); !refCore.Equal(&core) {
+			continue
+		}
+		prefix, number, err := ParsePreRelease(ref.Version.Prerelease())
+		if err != nil || prefix != identifier+"." {
+			continue
+		}
+		commit, err := git.TagCommit(repo, ref.Ref)
+		if err != nil {
+			return nil, err
+		}
+		if commit == head.Hash() {
+			return ref.Version, nil
+		}
+		if number > counter {
+			counter = number
+		}
+	}
+	newVersion, err := setPreRelease(core, identifier+"."+strconv.Itoa(counter+1))
+	if err != nil {
+		return nil, err
+	}
+	if err := createTags(repo, newVersion, options); err != nil {
+		return nil, err
+	}
+	return &newVersion, nil
+}
+
+// channelCore returns the version without pre-release the channel pre-release is created for, latest is nil
+// without release. Outside version lines a new core version moves to the next version marker.
+func channelCore(repo *gogit.Repository, increment, identifier string, latest *semver.Version, line *release.VersionLine,
+	options Options) (semver.Version, error) {
+	if latest == nil && line != nil {
+		return *line.Initial(), nil
+	}
+	if latest == nil {
+		initialVersion, err := InitialVersion("minor", options)
+		if err != nil {
+			return semver.Version{}, err
+		}
+		return git.WithMarker(repo, options.TagPrefix, finalVersion(*initialVersion))
+	}
+	var core semver.Version
+	switch {
+	case strings.ToLower(increment) != "prerelease":
+		next, err := NextVersion(increment, *latest, identifier)
+		if err != nil {
+			return semver.Version{}, err
+		}
+		core = finalVersion(next)
+	case latest.Prerelease() != "":
+		return finalVersion(*latest), nil
+	default:
+		core = latest.IncPatch()
+	}
+	if line == nil {
+		return git.WithMarker(repo, options.TagPrefix, core)
+	}
+	if !line.Contains(&core) {
+		return semver.Version{}, fmt.Errorf("%w : %s %s of %s is outside %s of branch %s", release.ErrOutsideVersionLine,
+			increment, core.String(), latest.String(), line.String(), line.Branch)
+	}
+	return core, nil
+}
+
+// channelMatch accepts releases and the pre-releases of the channel identifier, on mainline the pre-releases of
+// PreReleaseIdentifier. It is nil without channels as every version is accepted.
+func channelMatch(identifier string, options Options) func(version *semver.Version) bool {
+	if len(options.Channels) == 0 {
+		return nil
+	}
+	own := identifier + "."
+	if identifier == "" {
+		own, _, _ = ParsePreRelease(StartPreRelease(options.PreReleaseIdentifier))
+	}
+	return func(version *semver.Version) bool {
+		if version.Prerelease() == "" {
+			return true
+		}
+		prefix, _, err := ParsePreRelease(version.Prerelease())
+		return err == nil && prefix == own
+	}
+}
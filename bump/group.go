@@ -0,0 +1,110 @@
+package bump
+
+import (
+	"errors"
+	"github.com/Masterminds/semver/v3"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/release"
+)
+
+// groupPrefixes returns the tag prefix followed by the other members of its version group.
+func groupPrefixes(options Options) []string {
+	prefixes := []string{options.TagPrefix}
+	for _, member := range options.VersionGroup {
+		found := false
+		for _, prefix := range prefixes {
+			found = found || prefix == member
+		}
+		if !found {
+			prefixes = append(prefixes, member)
+		}
+	}
+	return prefixes
+}
+
+// latestGroupRef returns the highest release of the version group, within the version line when set.
+func latestGroupRef(repo *gogit.Repository, prefixes []string, line *release.VersionLine, options Options) (git.SemverRef, error) {
+	var latest git.SemverRef
+	for _, prefix := range prefixes {
+		var ref git.SemverRef
+		var err error
+		switch {
+		case line != nil:
+			ref, err = git.LatestRefInLine(repo, prefix, *line)
+		case options.NearestRelease:
+			ref, err = git.NearestTag(repo, prefix)
+		default:
+			ref, err = git.LatestRef(repo, prefix)
+		}
+		switch {
+		case errors.Is(err, git.ErrNoTagFound):
+			continue
+		case err != nil:
+			return git.SemverRef{}, err
+		}
+		if latest.Version == nil || ref.Version.GreaterThan(latest.Version) {
+			latest = ref
+		}
+	}
+	if latest.Version == nil {
+		return git.SemverRef{}, git.ErrNoTagFound
+	}
+	return latest, nil
+}
+
+// taggedAtHEAD returns true when every prefix has the version tag on HEAD.
+func taggedAtHEAD(repo *gogit.Repository, head plumbing.Hash, prefixes []string, version *semver.Version) (bool, error) {
+	for _, prefix := range prefixes {
+		ref, err := repo.Tag(prefix + version.String())
+		if errors.Is(err, gogit.ErrTagNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		commit, err := git.TagCommit(repo, ref)
+		if err != nil {
+			return false, err
+		}
+		if commit != head {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// bumpGroup tags HEAD with the next version of the group for every member.
+func bumpGroup(repo *gogit.Repository, increment string, line *release.VersionLine, options Options) (*semver.Version, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	prefixes := groupPrefixes(options)
+	latest, err := latestGroupRef(repo, prefixes, line, options)
+	var newVersion semver.Version
+	switch {
+	case errors.Is(err, git.ErrNoTagFound):
+		if newVersion, err = firstRelease(repo, increment, line, options); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		tagged, err := taggedAtHEAD(repo, head.Hash(), prefixes, latest.Version)
+		if err != nil {
+			return nil, err
+		}
+		if tagged {
+			return latest.Version, nil
+		}
+		if newVersion, err = nextVersion(repo, increment, latest, line, options); err != nil {
+			return nil, err
+		}
+	}
+	if err := createTags(repo, newVersion, options); err != nil {
+		return nil, err
+	}
+	return &newVersion, nil
+}
@@ -0,0 +1,39 @@
+package bump
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/release"
+)
+
+var (
+	ErrInvalidMarker = errors.New("invalid next version marker")
+)
+
+type MarkNextFunc func(repo *gogit.Repository, version *semver.Version, options Options) (*semver.Version, error)
+
+// MarkNext tags HEAD with a next version marker greater than the release bump increments.
+func MarkNext(repo *gogit.Repository, version *semver.Version, options Options) (*semver.Version, error) {
+	if version.Prerelease() != "" || version.Metadata() != "" {
+		return nil, fmt.Errorf("%w : %s must not have pre-release or metadata", ErrInvalidMarker, version.String())
+	}
+	line, err := release.CurrentVersionLine(repo, options.Remote, options.VersionLines)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := latestRelease(repo, line, "", options)
+	switch {
+	case errors.Is(err, git.ErrNoTagFound):
+	case err != nil:
+		return nil, err
+	case !options.AllowLower && !version.GreaterThan(latest.Version):
+		return nil, fmt.Errorf("%w : %s <= %s", ErrVersionNotGreater, version.String(), latest.Version.String())
+	}
+	if err := git.CreateMarker(repo, version.String(), options.TagPrefix, options.DryRun); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
@@ -0,0 +1,111 @@
+package bump
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const DefaultPropagationIncrement = "patch"
+
+var (
+	ErrDependencyCycle    = errors.New("dependency cycle")
+	ErrUnknownDependency  = errors.New("unknown dependency")
+	releaseIncrementRanks = map[string]int{"patch": 1, "minor": 2, "major": 3}
+)
+
+// Step is a planned bump, Cause is the released dependency the bump propagates from, empty for a direct bump.
+type Step struct {
+	Project   string
+	Increment string
+	Cause     string
+}
+
+func (s Step) String() string {
+	if s.Cause == "" {
+		return fmt.Sprintf("%s %s", s.Project, s.Increment)
+	}
+	return fmt.Sprintf("%s %s (depends on %s)", s.Project, s.Increment, s.Cause)
+}
+
+// Plan orders the bumps by dependency, dependants of released projects get the highest of the increments.
+func Plan(projects []string, dependsOn map[string][]string, increments map[string]string,
+	propagation map[string]string) ([]Step, error) {
+	known := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		known[project] = true
+	}
+	order, err := topologicalOrder(projects, dependsOn, known)
+	if err != nil {
+		return nil, err
+	}
+	released := make(map[string]bool)
+	var steps []Step
+	for _, project := range order {
+		step := Step{Project: project, Increment: increments[project]}
+		dependencies := append([]string(nil), dependsOn[project]...)
+		sort.Strings(dependencies)
+		for _, dependency := range dependencies {
+			if !released[dependency] {
+				continue
+			}
+			propagated := propagation[project]
+			if propagated == "" {
+				propagated = DefaultPropagationIncrement
+			}
+			if step.Increment == "" || (releaseIncrementRanks[step.Increment] > 0 &&
+				releaseIncrementRanks[propagated] > releaseIncrementRanks[step.Increment]) {
+				step.Increment, step.Cause = propagated, dependency
+			}
+		}
+		if step.Increment != "" {
+			released[project] = true
+			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+// topologicalOrder returns the projects with dependencies first, projects are otherwise visited in sorted order.
+func topologicalOrder(projects []string, dependsOn map[string][]string, known map[string]bool) ([]string, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	sorted := append([]string(nil), projects...)
+	sort.Strings(sorted)
+	state := make(map[string]int, len(projects))
+	var order, path []string
+	var visit func(project string) error
+	visit = func(project string) error {
+		switch state[project] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w : %s -> %s", ErrDependencyCycle, strings.Join(path, " -> "), project)
+		}
+		state[project] = visiting
+		path = append(path, project)
+		dependencies := append([]string(nil), dependsOn[project]...)
+		sort.Strings(dependencies)
+		for _, dependency := range dependencies {
+			if !known[dependency] {
+				return fmt.Errorf("%w : %s depends on %s", ErrUnknownDependency, project, dependency)
+			}
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[project] = visited
+		order = append(order, project)
+		return nil
+	}
+	for _, project := range sorted {
+		if err := visit(project); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
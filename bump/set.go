@@ -0,0 +1,78 @@
+package bump
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/release"
+)
+
+var (
+	ErrVersionNotGreater = errors.New("version is not greater than the latest release")
+)
+
+type SetFunc func(repo *gogit.Repository, version *semver.Version, options Options) (*semver.Version, error)
+
+// Set tags HEAD with the version, which must be greater than the latest release unless options.AllowLower is set.
+// Setting the version HEAD is already tagged with returns the version. On a maintenance branch the version must be
+// inside the version line of the branch and is compared with the latest release of the line. With a version group
+// every member is tagged and the version is compared with the highest release across the group.
+func Set(repo *gogit.Repository, version *semver.Version, options Options) (*semver.Version, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	line, err := validateHEAD(repo, options)
+	if err != nil {
+		return nil, err
+	}
+	if line != nil && !line.Contains(version) {
+		return nil, fmt.Errorf("%w : %s is outside %s of branch %s", release.ErrOutsideVersionLine,
+			version.String(), line.String(), line.Branch)
+	}
+	prefixes := groupPrefixes(options)
+	tagged := 0
+	for _, prefix := range prefixes {
+		tag := prefix + version.String()
+		ref, err := repo.Tag(tag)
+		if errors.Is(err, gogit.ErrTagNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		commit, err := git.TagCommit(repo, ref)
+		if err != nil {
+			return nil, err
+		}
+		if commit != head.Hash() {
+			return nil, fmt.Errorf("%w : %s", gogit.ErrTagExists, tag)
+		}
+		tagged++
+	}
+	if tagged == len(prefixes) {
+		return version, nil
+	}
+
+	var latest git.SemverRef
+	if len(options.VersionGroup) > 0 {
+		latest, err = latestGroupRef(repo, prefixes, line, options)
+	} else if line != nil {
+		latest, err = git.LatestRefInLine(repo, options.TagPrefix, *line)
+	} else {
+		latest, err = git.LatestRef(repo, options.TagPrefix)
+	}
+	switch {
+	case errors.Is(err, git.ErrNoTagFound):
+	case err != nil:
+		return nil, err
+	case !options.AllowLower && !version.GreaterThan(latest.Version):
+		return nil, fmt.Errorf("%w : %s <= %s", ErrVersionNotGreater, version.String(), latest.Version.String())
+	}
+	if err := createTags(repo, *version, options); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
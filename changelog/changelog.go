@@ -0,0 +1,313 @@
+package changelog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sky-uk/vergo/release"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	MarkdownFormat = "markdown"
+	JSONFormat     = "json"
+	// DateFormat is the ISO 8601 date format of the markdown changelog, as Keep a Changelog expects
+	DateFormat = "2006-01-02"
+	// MarkdownTemplate renders a Changelog as markdown, user templates receive the same data
+	MarkdownTemplate = `## [{{ .Title }}] - {{ .Date.Format "` + DateFormat + `" }}` + sectionsTemplate
+	// FileTemplate renders the section of a release in a Keep a Changelog file e.g. ## [1.2.0] - 2026-10-17
+	FileTemplate = `## [{{ .To }}] - {{ .Date.Format "` + DateFormat + `" }}` + sectionsTemplate
+	// DefaultFile is the changelog file updated on bump
+	DefaultFile = "CHANGELOG.md"
+)
+
+const sectionsTemplate = `
+{{- range .Sections }}
+
+### {{ .Title }}
+{{ range .Commits }}
+- {{ if .Breaking }}**BREAKING** {{ end }}{{ if .Scope }}**{{ .Scope }}:** {{ end }}{{ .Subject }} ({{ .ShortHash }})
+{{- end }}
+{{- end }}
+`
+
+var (
+	ErrInvalidFormat   = errors.New("invalid changelog format")
+	ErrInvalidTemplate = errors.New("invalid changelog template")
+)
+
+// sectionTitles are the known conventional commit types in changelog order.
+var sectionTitles = []struct{ commitType, title string }{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+	{"refactor", "Code Refactoring"},
+	{"docs", "Documentation"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"test", "Tests"},
+	{"style", "Styles"},
+	{"chore", "Chores"},
+}
+
+const otherTitle = "Other Changes"
+
+type Commit struct {
+	Hash      string `json:"hash"`
+	ShortHash string `json:"shortHash"`
+	Type      string `json:"type,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Subject   string `json:"subject"`
+	Breaking  bool   `json:"breaking,omitempty"`
+	Author    string `json:"author"`
+}
+
+// Section groups the commits of a conventional commit type, Type is empty for the commits which are not conventional.
+type Section struct {
+	Title   string   `json:"title"`
+	Type    string   `json:"type"`
+	Commits []Commit `json:"commits"`
+}
+
+// Changelog lists the commits from the previous release to the release or HEAD.
+type Changelog struct {
+	TagPrefix string    `json:"tagPrefix"`
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	Date      time.Time `json:"date"`
+	Sections  []Section `json:"sections"`
+}
+
+// Title returns the tag of the release e.g. app-1.2.0, HEAD for the unreleased changes.
+func (c *Changelog) Title() string {
+	if c.To == "HEAD" {
+		return c.To
+	}
+	return c.TagPrefix + c.To
+}
+
+// Options select the commits of a changelog, merge commits are left out unless IncludeMerges is set.
+type Options struct {
+	Filter        release.PathFilter
+	IncludeMerges bool
+}
+
+// Generate groups the commits between since and until, plumbing.ZeroHash since for the whole history.
+func Generate(repo *gogit.Repository, since, until plumbing.Hash, options Options) (*Changelog, error) {
+	commits, err := release.CommitsBetween(repo, since, until)
+	if err != nil {
+		return nil, err
+	}
+	date, err := commitDate(repo, until)
+	if err != nil {
+		return nil, err
+	}
+	changelog := &Changelog{Date: date, Sections: []Section{}}
+	sections := make(map[string]*Section)
+	var types []string
+	for _, commit := range commits {
+		if commit.NumParents() > 1 && !options.IncludeMerges {
+			continue
+		}
+		match, err := matchCommit(commit, options.Filter)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
+		entry := newCommit(commit)
+		section, ok := sections[entry.Type]
+		if !ok {
+			section = &Section{Title: sectionTitle(entry.Type), Type: entry.Type}
+			sections[entry.Type] = section
+			types = append(types, entry.Type)
+		}
+		section.Commits = append(section.Commits, entry)
+	}
+	for _, known := range sectionTitles {
+		if section, ok := sections[known.commitType]; ok {
+			changelog.Sections = append(changelog.Sections, *section)
+		}
+	}
+	for _, commitType := range types {
+		if commitType != "" && sectionTitle(commitType) == commitType {
+			changelog.Sections = append(changelog.Sections, *sections[commitType])
+		}
+	}
+	if section, ok := sections[""]; ok {
+		changelog.Sections = append(changelog.Sections, *section)
+	}
+	return changelog, nil
+}
+
+func commitDate(repo *gogit.Repository, hash plumbing.Hash) (time.Time, error) {
+	if tagObject, err := repo.TagObject(hash); err == nil {
+		hash = tagObject.Target
+	} else if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return time.Time{}, err
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return commit.Committer.When, nil
+}
+
+func sectionTitle(commitType string) string {
+	if commitType == "" {
+		return otherTitle
+	}
+	for _, known := range sectionTitles {
+		if known.commitType == commitType {
+			return known.title
+		}
+	}
+	return commitType
+}
+
+func newCommit(commit *object.Commit) Commit {
+	entry := Commit{
+		Hash:      commit.Hash.String(),
+		ShortHash: commit.Hash.String()[:7],
+		Author:    commit.Author.Name,
+	}
+	if conventional, ok := release.ParseConventionalCommit(commit.Message); ok {
+		entry.Type, entry.Scope, entry.Subject, entry.Breaking =
+			conventional.Type, conventional.Scope, conventional.Description, conventional.Breaking
+	} else {
+		entry.Subject = strings.TrimSpace(strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0])
+	}
+	return entry
+}
+
+// matchCommit returns true when the commit changes a file selected by the filter.
+func matchCommit(commit *object.Commit, filter release.PathFilter) (bool, error) {
+	if len(filter.Paths) == 0 && len(filter.IgnorePaths) == 0 {
+		return true, nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, err
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return false, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return false, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return false, err
+	}
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name == "" {
+				continue
+			}
+			if match, err := filter.Match(name); err != nil || match {
+				return match, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// Render writes the changelog in the format, markdown or json.
+func Render(w io.Writer, changelog *Changelog, format string) error {
+	switch strings.ToLower(format) {
+	case MarkdownFormat, "md", "":
+		return RenderTemplate(w, changelog, MarkdownTemplate)
+	case JSONFormat:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(changelog)
+	default:
+		return fmt.Errorf("%w : %s", ErrInvalidFormat, format)
+	}
+}
+
+// RenderTemplate writes the changelog with the text/template.
+func RenderTemplate(w io.Writer, changelog *Changelog, text string) error {
+	tmpl, err := template.New("changelog").Parse(text)
+	if err != nil {
+		return fmt.Errorf("%w : %s", ErrInvalidTemplate, err)
+	}
+	if err := tmpl.Execute(w, changelog); err != nil {
+		return fmt.Errorf("%w : %s", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+// RenderTemplateFile writes the changelog with the text/template of the file.
+func RenderTemplateFile(w io.Writer, changelog *Changelog, file string) error {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return RenderTemplate(w, changelog, string(text))
+}
+
+var releaseHeading = regexp.MustCompile(`(?m)^## \[`)
+
+// Insert adds the section of a release above the latest release of the changelog file.
+func Insert(content, section string) string {
+	if strings.TrimSpace(content) == "" {
+		return "# Changelog\n\n" + section
+	}
+	section = strings.TrimRight(section, "\n") + "\n\n"
+	for _, match := range releaseHeading.FindAllStringIndex(content, -1) {
+		if strings.HasPrefix(content[match[0]:], "## [Unreleased]") {
+			continue
+		}
+		return content[:match[0]] + section + content[match[0]:]
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + strings.TrimRight(section, "\n") + "\n"
+}
+
+// Diff returns the unified diff of the file from before to after as a single hunk.
+func Diff(file, before, after string) string {
+	beforeLines, afterLines := splitLines(before), splitLines(after)
+	prefix := 0
+	for prefix < len(beforeLines) && prefix < len(afterLines) && beforeLines[prefix] == afterLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(beforeLines)-prefix && suffix < len(afterLines)-prefix &&
+		beforeLines[len(beforeLines)-1-suffix] == afterLines[len(afterLines)-1-suffix] {
+		suffix++
+	}
+	removed, added := beforeLines[prefix:len(beforeLines)-suffix], afterLines[prefix:len(afterLines)-suffix]
+	if len(removed) == 0 && len(added) == 0 {
+		return ""
+	}
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %s\n+++ %s\n@@ -%d,%d +%d,%d @@\n", file, file, prefix+1, len(removed), prefix+1, len(added))
+	for _, line := range removed {
+		diff.WriteString("-" + line + "\n")
+	}
+	for _, line := range added {
+		diff.WriteString("+" + line + "\n")
+	}
+	return diff.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,152 @@
+package changelog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/sky-uk/vergo/changelog"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/sky-uk/vergo/release"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateShouldGroupCommitsByType(t *testing.T) {
+	repo, _ := PersistentRepository(t)
+	DoCommitWithMessage(t, repo, "init", "initial commit")
+	head, err := repo.Head()
+	assert.Nil(t, err)
+	since := head.Hash()
+	DoCommitWithMessage(t, repo, "api/a", "fix(api): handle empty body")
+	DoCommitWithMessage(t, repo, "web/b", "feat: dark mode")
+	DoCommitWithMessage(t, repo, "api/c", "feat(api)!: drop v1 endpoints")
+	DoCommitWithMessage(t, repo, "docs/d", "update readme")
+	DoCommitWithMessage(t, repo, "api/e", "deps: bump go-git")
+	head, err = repo.Head()
+	assert.Nil(t, err)
+
+	changelog, err := Generate(repo, since, head.Hash(), Options{})
+	assert.Nil(t, err)
+	var titles []string
+	for _, section := range changelog.Sections {
+		var subjects []string
+		for _, commit := range section.Commits {
+			subjects = append(subjects, commit.Subject)
+		}
+		titles = append(titles, section.Title+": "+strings.Join(subjects, ", "))
+	}
+	assert.Equal(t, []string{
+		"Features: drop v1 endpoints, dark mode",
+		"Bug Fixes: handle empty body",
+		"deps: bump go-git",
+		"Other Changes: update readme",
+	}, titles)
+	assert.True(t, changelog.Sections[0].Commits[0].Breaking)
+	assert.Equal(t, "api", changelog.Sections[0].Commits[0].Scope)
+
+	changelog, err = Generate(repo, since, head.Hash(), Options{Filter: release.PathFilter{Paths: []string{"api"}}})
+	assert.Nil(t, err)
+	assert.Len(t, changelog.Sections, 3)
+
+	changelog, err = Generate(repo, plumbing.ZeroHash, since, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, "initial commit", changelog.Sections[0].Commits[0].Subject)
+}
+
+func TestRenderShouldWriteMarkdownJSONAndTemplates(t *testing.T) {
+	repo, _ := PersistentRepository(t)
+	DoCommitWithMessage(t, repo, "a", "feat(api): add orders")
+	DoCommitWithMessage(t, repo, "b", "fix!: reject invalid ids")
+	head, err := repo.Head()
+	assert.Nil(t, err)
+	changelog, err := Generate(repo, plumbing.ZeroHash, head.Hash(), Options{})
+	assert.Nil(t, err)
+	changelog.TagPrefix, changelog.To = "app-", "1.0.0"
+	hashes := []string{changelog.Sections[0].Commits[0].ShortHash, changelog.Sections[1].Commits[0].ShortHash}
+
+	var markdown bytes.Buffer
+	assert.Nil(t, Render(&markdown, changelog, MarkdownFormat))
+	assert.Equal(t, "## [app-1.0.0] - 2017-05-04\n\n"+
+		"### Features\n\n- **api:** add orders ("+hashes[0]+")\n\n"+
+		"### Bug Fixes\n\n- **BREAKING** reject invalid ids ("+hashes[1]+")\n", markdown.String())
+
+	var encoded bytes.Buffer
+	assert.Nil(t, Render(&encoded, changelog, JSONFormat))
+	var decoded Changelog
+	assert.Nil(t, json.Unmarshal(encoded.Bytes(), &decoded))
+	assert.Equal(t, "1.0.0", decoded.To)
+	assert.Equal(t, "feat", decoded.Sections[0].Type)
+
+	var custom bytes.Buffer
+	assert.Nil(t, RenderTemplate(&custom, changelog, "{{ .Title }}{{ range .Sections }} {{ len .Commits }}{{ end }}"))
+	assert.Equal(t, "app-1.0.0 1 1", custom.String())
+
+	assert.ErrorIs(t, Render(&custom, changelog, "xml"), ErrInvalidFormat)
+	assert.ErrorIs(t, RenderTemplate(&custom, changelog, "{{ .Missing }}"), ErrInvalidTemplate)
+}
+
+func TestInsertShouldAddReleaseAboveLatestRelease(t *testing.T) {
+	section := "## [1.1.0] - 2026-10-17\n\n### Features\n\n- orders (abc1234)\n"
+	testCases := []struct {
+		content, expected string
+	}{
+		{
+			content:  "",
+			expected: "# Changelog\n\n" + section,
+		},
+		{
+			content:  "# Changelog\n\n## [Unreleased]\nwip\n\n## [1.0.0] - 2026-10-01\nfirst\n",
+			expected: "# Changelog\n\n## [Unreleased]\nwip\n\n" + section + "\n## [1.0.0] - 2026-10-01\nfirst\n",
+		},
+		{
+			content:  "# Changelog\n\n## [Unreleased]\n",
+			expected: "# Changelog\n\n## [Unreleased]\n\n" + section,
+		},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, Insert(testCase.content, section))
+	}
+}
+
+func TestDiffShouldShowInsertedLines(t *testing.T) {
+	before := "# Changelog\n\n## [1.0.0]\n"
+	after := Insert(before, "## [1.1.0]\n- orders\n")
+	assert.Equal(t, "--- CHANGELOG.md\n+++ CHANGELOG.md\n@@ -3,0 +3,3 @@\n+## [1.1.0]\n+- orders\n+\n", Diff("CHANGELOG.md", before, after))
+	assert.Empty(t, Diff("CHANGELOG.md", before, before))
+}
+
+func TestGenerateShouldListMergeCommitsWhenIncluded(t *testing.T) {
+	repo, _ := PersistentRepository(t)
+	DoCommitWithMessage(t, repo, "init", "initial commit")
+	base, err := repo.Head()
+	assert.Nil(t, err)
+	DoCommitWithMessage(t, repo, "api/a", "fix(api): handle empty body")
+	side, err := repo.Head()
+	assert.Nil(t, err)
+	w, err := repo.Worktree()
+	assert.Nil(t, err)
+	assert.Nil(t, w.Checkout(&gogit.CheckoutOptions{Hash: base.Hash(), Branch: plumbing.NewBranchReferenceName("main"), Create: true}))
+	DoCommitWithMessage(t, repo, "web/b", "feat: dark mode")
+	head, err := repo.Head()
+	assert.Nil(t, err)
+	signature := &object.Signature{Name: "vergo", Email: "vergo@example.com", When: time.Now()}
+	merge, err := w.Commit("Merge branch 'fix'", &gogit.CommitOptions{
+		Author:    signature,
+		Committer: signature,
+		Parents:   []plumbing.Hash{head.Hash(), side.Hash()},
+	})
+	assert.Nil(t, err)
+
+	changelog, err := Generate(repo, base.Hash(), merge, Options{})
+	assert.Nil(t, err)
+	assert.Len(t, changelog.Sections, 2)
+
+	changelog, err = Generate(repo, base.Hash(), merge, Options{IncludeMerges: true})
+	assert.Nil(t, err)
+	assert.Len(t, changelog.Sections, 3)
+	assert.Equal(t, "Merge branch 'fix'", changelog.Sections[2].Commits[0].Subject)
+}
@@ -0,0 +1,177 @@
+// Package client is the Go API of vergo, the commands of the cli are wrappers over it.
+//
+//	c, err := client.New(ctx, ".", client.Options{})
+//	project := c.Project("app", client.ProjectOptions{Paths: []string{"services/app/**"}})
+//	version, err := project.Bump(ctx, "auto", client.BumpOptions{ConventionalCommits: true})
+//	err = project.Push(ctx, version)
+package client
+
+import (
+	"context"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/sky-uk/vergo/bump"
+	"github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/release"
+	"strings"
+)
+
+const (
+	DefaultRemote         = "origin"
+	DefaultTokenEnvVarKey = "GH_TOKEN"
+)
+
+// DefaultVersionedBranches are the main working branches when Options.VersionedBranches is nil
+var DefaultVersionedBranches = []string{"master", "main"}
+
+// Options configure the Client, the zero value uses the defaults of the cli.
+type Options struct {
+	// Remote is the remote of the versioned branches and of the pushes, DefaultRemote when empty
+	Remote string
+	// VersionedBranches are the branches releases are made from, DefaultVersionedBranches when nil
+	VersionedBranches []string
+	// VersionLines are the branch patterns of maintenance branches e.g. release/{major}.{minor}, none when nil
+	VersionLines []string
+	// NearestRelease uses the nearest tag in the commit history instead of the highest tag
+	NearestRelease bool
+	// DryRun logs the tags and pushes instead of creating them
+	DryRun bool
+	Auth   Auth
+	// Backend replaces the functions called by the client, e.g. in tests
+	Backend Backend
+}
+
+// Auth is the authentication of the pushes, the token of TokenEnvVarKey or the ssh agent.
+type Auth struct {
+	// TokenEnvVarKey is DefaultTokenEnvVarKey when empty
+	TokenEnvVarKey            string
+	DisableStrictHostChecking bool
+}
+
+// RefFunc returns a release of the tag prefix.
+type RefFunc func(repo *gogit.Repository, prefix string) (git.SemverRef, error)
+
+// Backend are the functions called by the client, nil functions use the vergo implementations.
+type Backend struct {
+	Latest, Previous RefFunc
+	Current          git.CurrentVersionFunc
+	Bump             bump.Func
+	Set              bump.SetFunc
+	MarkNext         bump.MarkNextFunc
+	PushTag          git.PushTagFunc
+	PushTags         git.PushTagsFunc
+	SkipHintPresent  release.SkipHintPresentFunc
+	ValidateHEAD     release.ValidateHEADFunc
+	IncrementHint    release.IncrementHintFunc
+}
+
+func (b Backend) withDefaults() Backend {
+	if b.Latest == nil {
+		b.Latest = git.LatestRef
+	}
+	if b.Previous == nil {
+		b.Previous = git.PreviousRef
+	}
+	if b.Current == nil {
+		b.Current = git.CurrentVersion
+	}
+	if b.Bump == nil {
+		b.Bump = bump.Bump
+	}
+	if b.Set == nil {
+		b.Set = bump.Set
+	}
+	if b.MarkNext == nil {
+		b.MarkNext = bump.MarkNext
+	}
+	if b.SkipHintPresent == nil {
+		b.SkipHintPresent = release.SkipHintPresent
+	}
+	if b.ValidateHEAD == nil {
+		b.ValidateHEAD = release.ValidateHEAD
+	}
+	if b.IncrementHint == nil {
+		b.IncrementHint = release.IncrementHint
+	}
+	return b
+}
+
+// Client versions the projects of a repository.
+type Client struct {
+	repo    *gogit.Repository
+	options Options
+}
+
+// New opens the repository of the directory, or of one of its parents.
+func New(ctx context.Context, dir string, options Options) (*Client, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, err
+	}
+	return NewWithRepository(repo, options), nil
+}
+
+// NewWithRepository returns the client of an open repository.
+func NewWithRepository(repo *gogit.Repository, options Options) *Client {
+	if options.Remote == "" {
+		options.Remote = DefaultRemote
+	}
+	if options.VersionedBranches == nil {
+		options.VersionedBranches = DefaultVersionedBranches
+	}
+	if options.Auth.TokenEnvVarKey == "" {
+		options.Auth.TokenEnvVarKey = DefaultTokenEnvVarKey
+	}
+	options.Backend = options.Backend.withDefaults()
+	return &Client{repo: repo, options: options}
+}
+
+// Repository returns the repository of the client.
+func (c *Client) Repository() *gogit.Repository {
+	return c.repo
+}
+
+// PushTags pushes the tags to the remote with Backend.PushTags, or with git.PushTagsContext when it is nil.
+func (c *Client) PushTags(ctx context.Context, tags []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	o := c.options
+	if o.Backend.PushTags != nil {
+		return o.Backend.PushTags(c.repo, tags, o.Remote, o.DryRun, o.Auth.DisableStrictHostChecking, o.Auth.TokenEnvVarKey)
+	}
+	return git.PushTagsContext(ctx, c.repo, tags, git.PushOptions{
+		Remote:                    o.Remote,
+		DryRun:                    o.DryRun,
+		DisableStrictHostChecking: o.Auth.DisableStrictHostChecking,
+		TokenEnvVarKey:            o.Auth.TokenEnvVarKey,
+	})
+}
+
+// Project returns the project versioned with the tag prefix of the name, see TagPrefix.
+func (c *Client) Project(name string, options ProjectOptions) *Project {
+	group := make([]string, 0, len(options.VersionGroup))
+	for _, member := range options.VersionGroup {
+		group = append(group, TagPrefix(member))
+	}
+	options.VersionGroup = group
+	return &Project{client: c, name: name, prefix: TagPrefix(name), options: options}
+}
+
+// TagPrefix returns the tag prefix of a project name e.g. app- for app and v for an empty name.
+func TagPrefix(name string) string {
+	switch name := strings.ToLower(strings.TrimSpace(name)); {
+	case name == "":
+		return "v"
+	case name == "v":
+		return "v"
+	case strings.HasSuffix(name, "-"):
+		return name
+	case strings.HasSuffix(name, "/v"):
+		return name
+	default:
+		return name + "-"
+	}
+}
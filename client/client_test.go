@@ -0,0 +1,187 @@
+package client_test
+
+import (
+	"context"
+	"github.com/Masterminds/semver/v3"
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sky-uk/vergo/bump"
+	. "github.com/sky-uk/vergo/client"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/sky-uk/vergo/release"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func newClient(t *testing.T) (*Client, *gogit.Repository) {
+	t.Helper()
+	repo, tempDir := PersistentRepository(t)
+	c, err := New(context.Background(), tempDir, Options{})
+	assert.Nil(t, err)
+	return c, repo
+}
+
+func TestTagPrefix(t *testing.T) {
+	for name, prefix := range map[string]string{"": "v", "v": "v", "App": "app-", "app-": "app-", "app/v": "app/v"} {
+		assert.Equal(t, prefix, TagPrefix(name), name)
+	}
+}
+
+func TestProjectShouldBumpAndGetVersions(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newClient(t)
+	project := c.Project("app", ProjectOptions{})
+
+	current, err := project.Current(ctx, CurrentOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "0.0.0-SNAPSHOT", current.String())
+
+	for _, expected := range []string{"0.1.0", "0.2.0"} {
+		DoCommit(t, repo, expected)
+		next, err := project.Next(ctx, "minor", BumpOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, expected, next.String())
+		version, err := project.Bump(ctx, "minor", BumpOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, expected, version.String())
+	}
+	_, err = repo.Tag("app-0.2.0")
+	assert.Nil(t, err)
+
+	latest, err := project.Latest(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.2.0", latest.String())
+	previous, err := project.Previous(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.1.0", previous.String())
+
+	DoCommitWithMessage(t, repo, "third", "feat: third")
+	version, err := project.Bump(ctx, "auto", BumpOptions{ConventionalCommits: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "0.3.0", version.String())
+	current, err = project.Current(ctx, CurrentOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "0.3.0", current.String())
+}
+
+func TestProjectShouldSetAndTagVersionGroups(t *testing.T) {
+	c, repo := newClient(t)
+	DoCommit(t, repo, "first")
+	project := c.Project("api", ProjectOptions{VersionGroup: []string{"web"}})
+
+	version, err := project.Set(context.Background(), semver.MustParse("1.0.0"), SetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"api-1.0.0", "web-1.0.0"}, project.Tags(version))
+	for _, tag := range project.Tags(version) {
+		_, err := repo.Tag(tag)
+		assert.Nil(t, err, tag)
+	}
+}
+
+func TestProjectShouldPushTheTags(t *testing.T) {
+	c, repo := newClient(t)
+	DoCommit(t, repo, "first")
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	remote, err := gogit.PlainInit(remoteDir, true)
+	assert.Nil(t, err)
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: DefaultRemote, URLs: []string{remoteDir}})
+	assert.Nil(t, err)
+	t.Setenv(DefaultTokenEnvVarKey, "token")
+
+	project := c.Project("app", ProjectOptions{})
+	version, err := project.Bump(context.Background(), "minor", BumpOptions{})
+	assert.Nil(t, err)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, project.Push(cancelled, version), context.Canceled)
+	_, err = remote.Tag("app-0.1.0")
+	assert.ErrorIs(t, err, gogit.ErrTagNotFound)
+
+	assert.Nil(t, project.Push(context.Background(), version))
+	_, err = remote.Tag("app-0.1.0")
+	assert.Nil(t, err)
+}
+
+func TestProjectShouldMarkAndPushTheNextVersion(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newClient(t)
+	DoCommit(t, repo, "first")
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	remote, err := gogit.PlainInit(remoteDir, true)
+	assert.Nil(t, err)
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: DefaultRemote, URLs: []string{remoteDir}})
+	assert.Nil(t, err)
+	t.Setenv(DefaultTokenEnvVarKey, "token")
+
+	project := c.Project("app", ProjectOptions{})
+	marker, err := project.Mark(ctx, semver.MustParse("2.0.0"), MarkOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, project.PushMark(ctx, marker))
+	_, err = remote.Tag("vergo-next/app-2.0.0")
+	assert.Nil(t, err)
+
+	version, err := project.Bump(ctx, "minor", BumpOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "2.0.0", version.String())
+	_, err = project.Mark(ctx, semver.MustParse("1.5.0"), MarkOptions{})
+	assert.ErrorIs(t, err, bump.ErrVersionNotGreater)
+	_, err = project.Mark(ctx, semver.MustParse("1.5.0"), MarkOptions{AllowLower: true})
+	assert.Nil(t, err)
+}
+
+func TestProjectChecks(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newClient(t)
+	DoCommitWithMessage(t, repo, "api/first", "[vergo:api:minor-release] first")
+	project := c.Project("api", ProjectOptions{Paths: []string{"api/**"}})
+
+	result, err := project.Check(ctx, CheckIncrementHint)
+	assert.Nil(t, err)
+	assert.Equal(t, "minor", result.Increment)
+	_, err = project.Check(ctx, CheckRelease)
+	assert.Nil(t, err)
+
+	_, err = project.Bump(ctx, "auto", BumpOptions{})
+	assert.Nil(t, err)
+	_, err = project.Check(ctx, CheckChanged)
+	assert.ErrorIs(t, err, release.ErrNoChanges)
+	_, err = project.Bump(ctx, "minor", BumpOptions{OnlyIfChanged: true})
+	assert.ErrorIs(t, err, release.ErrNoChanges)
+	DoCommit(t, repo, "api/second")
+	result, err = project.Check(ctx, CheckChanged)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"api/second"}, result.Changed)
+	_, err = project.Check(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrUnknownCheck)
+}
+
+func TestProjectShouldCompareWithTheLatestReleaseOfTheVersionLine(t *testing.T) {
+	ctx := context.Background()
+	repo, tempDir := PersistentRepository(t)
+	c, err := New(ctx, tempDir, Options{VersionedBranches: []string{"master", "release/*"}, VersionLines: []string{"release/{major}.{minor}"}})
+	assert.Nil(t, err)
+	project := c.Project("api", ProjectOptions{Paths: []string{"api/**"}})
+	DoCommit(t, repo, "api/first")
+	_, err = project.Set(ctx, semver.MustParse("1.4.0"), SetOptions{})
+	assert.Nil(t, err)
+	head, err := repo.Head()
+	assert.Nil(t, err)
+	DoCommitWithMessage(t, repo, "api/second", "feat: second")
+	_, err = project.Bump(ctx, "minor", BumpOptions{})
+	assert.Nil(t, err)
+
+	w, err := repo.Worktree()
+	assert.Nil(t, err)
+	err = w.Checkout(&gogit.CheckoutOptions{Hash: head.Hash(), Branch: plumbing.NewBranchReferenceName("release/1.4"), Create: true})
+	assert.Nil(t, err)
+	DoCommitWithMessage(t, repo, "api/fix", "fix: on the version line")
+	result, err := project.Check(ctx, CheckChanged)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"api/fix"}, result.Changed)
+	version, err := project.Bump(ctx, "auto", BumpOptions{ConventionalCommits: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "1.4.1", version.String())
+}
@@ -0,0 +1,381 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sky-uk/vergo/bump"
+	"github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/release"
+)
+
+var ErrUnknownCheck = errors.New("unknown check")
+
+// ProjectOptions configure a Project.
+type ProjectOptions struct {
+	// InitialVersion is the first release when there is no tag, 0.1.0 when empty. Current returns it as a SNAPSHOT,
+	// 0.0.0-SNAPSHOT when empty
+	InitialVersion string
+	// Paths and IgnorePaths are the path globs of the project e.g. services/api/**, the whole repository when empty
+	Paths, IgnorePaths []string
+	// VersionGroup are the projects sharing the version of the project, bumping any of them tags every member
+	VersionGroup []string
+}
+
+// Project is a project of the repository, versioned by the tags of its tag prefix.
+type Project struct {
+	client       *Client
+	name, prefix string
+	options      ProjectOptions
+}
+
+// CurrentOptions configure the pre-release of the current version, see release.PreReleaseOptions.
+type CurrentOptions struct {
+	WithMetadata bool
+	// Strategy is one of release.SnapshotStrategies, the template strategy when Template is set
+	Strategy, Increment, Template string
+}
+
+// TagOptions configure the tags, they are lightweight unless Message returns a message.
+type TagOptions struct {
+	Message func(version semver.Version) (string, error)
+	// Tagger is the identity of annotated tags
+	Tagger *object.Signature
+	// Signer signs the annotated tags
+	Signer git.TagSigner
+}
+
+// BumpOptions configure Bump and Next.
+type BumpOptions struct {
+	// PreReleaseIdentifier starts new pre-releases, bump.DefaultPreReleaseIdentifier when empty
+	PreReleaseIdentifier string
+	// Channels map branch patterns to pre-release identifiers e.g. develop=beta
+	Channels []string
+	// InitialIncrement applies the increment to 0.0.0 for the first release, ignoring ProjectOptions.InitialVersion
+	InitialIncrement bool
+	// ConventionalCommits resolves auto from the conventional commits since the latest release instead of the
+	// increment hint, ScopeAsPrefix only counts the commits whose scope is the project
+	ConventionalCommits, ScopeAsPrefix bool
+	// OnlyIfChanged returns release.ErrNoChanges when no file of the project changed since the latest release
+	OnlyIfChanged bool
+	// PreTag is called with the new version before HEAD is tagged e.g. to commit release files
+	PreTag func(version semver.Version) error
+	Tag    TagOptions
+}
+
+// SetOptions configure Set.
+type SetOptions struct {
+	// AllowLower allows a version which is not greater than the latest release
+	AllowLower bool
+	Tag        TagOptions
+}
+
+// MarkOptions configure Mark.
+type MarkOptions struct {
+	// AllowLower allows a version which is not greater than the latest release
+	AllowLower bool
+}
+
+// Check is a validation of Project.Check.
+type Check string
+
+const (
+	// CheckRelease checks that HEAD can be released: no skip hint, on a versioned or maintenance branch
+	CheckRelease Check = "release"
+	// CheckIncrementHint checks that the last commit message has an increment hint unless it has a skip hint
+	CheckIncrementHint Check = "increment-hint"
+	// CheckChanged checks that files of the project changed since the latest release
+	CheckChanged Check = "changed"
+)
+
+// CheckResult is the result of a check, Skipped is the skip hint of a passed increment-hint check.
+type CheckResult struct {
+	Increment string
+	Changed   []string
+	Skipped   string
+}
+
+// Name returns the name of the project.
+func (p *Project) Name() string {
+	return p.name
+}
+
+// TagPrefix returns the tag prefix of the project.
+func (p *Project) TagPrefix() string {
+	return p.prefix
+}
+
+// Tags returns the tags of the version, one for every member of the version group.
+func (p *Project) Tags(version *semver.Version) []string {
+	tags := []string{p.prefix + version.String()}
+	for _, member := range p.options.VersionGroup {
+		if tag := member + version.String(); tag != tags[0] {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Latest returns the latest release.
+func (p *Project) Latest(ctx context.Context) (*semver.Version, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ref, err := p.client.options.Backend.Latest(p.client.repo, p.prefix)
+	return ref.Version, err
+}
+
+// Previous returns the release before the latest release.
+func (p *Project) Previous(ctx context.Context) (*semver.Version, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ref, err := p.client.options.Backend.Previous(p.client.repo, p.prefix)
+	return ref.Version, err
+}
+
+// Current returns the release of HEAD or the pre-release of the next version.
+func (p *Project) Current(ctx context.Context, options CurrentOptions) (*semver.Version, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	strategy := options.Strategy
+	if options.Template != "" {
+		strategy = release.TemplateStrategy
+	}
+	preRelease := release.PreRelease(p.client.repo, release.PreReleaseOptions{
+		WithMetadata: options.WithMetadata,
+		TagPrefix:    p.prefix,
+		Increment:    options.Increment,
+		Strategy:     strategy,
+		Template:     options.Template,
+	})
+	ref, err := p.client.options.Backend.Current(p.client.repo, p.prefix, preRelease,
+		git.GetOptions{NearestRelease: p.client.options.NearestRelease})
+	if errors.Is(err, plumbing.ErrReferenceNotFound) || errors.Is(err, git.ErrNoTagFound) {
+		return initialSnapshot(p.options.InitialVersion)
+	}
+	return ref.Version, err
+}
+
+func initialSnapshot(initialVersion string) (*semver.Version, error) {
+	if initialVersion == "" {
+		return semver.MustParse("0.0.0-SNAPSHOT"), nil
+	}
+	version, err := semver.NewVersion(initialVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", bump.ErrInvalidInitialVersion, initialVersion)
+	}
+	snapshot, err := version.SetPrerelease("SNAPSHOT")
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Increment checks the skip hint and the changes of the project and resolves auto.
+func (p *Project) Increment(ctx context.Context, increment string, options BumpOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	backend := p.client.options.Backend
+	if err := backend.SkipHintPresent(p.client.repo, p.name); err != nil {
+		return "", err
+	}
+	if options.OnlyIfChanged {
+		if _, err := p.changed(); err != nil {
+			return "", err
+		}
+	}
+	if increment != "auto" {
+		return increment, nil
+	}
+	if options.ConventionalCommits {
+		return p.conventionalIncrement(release.ConventionalOptions{ScopeAsPrefix: options.ScopeAsPrefix})
+	}
+	return backend.IncrementHint(p.client.repo, p.name)
+}
+
+// Next returns the version Bump would release, the release of HEAD when it is already released.
+func (p *Project) Next(ctx context.Context, increment string, options BumpOptions) (*semver.Version, error) {
+	increment, err := p.Increment(ctx, increment, options)
+	if err != nil {
+		return nil, err
+	}
+	return p.Preview(ctx, increment, options)
+}
+
+// Preview returns the version Release would tag without tagging, auto is not resolved.
+func (p *Project) Preview(ctx context.Context, increment string, options BumpOptions) (*semver.Version, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	bumpOptions := p.bumpOptions(BumpOptions{
+		PreReleaseIdentifier: options.PreReleaseIdentifier,
+		Channels:             options.Channels,
+		InitialIncrement:     options.InitialIncrement,
+	})
+	bumpOptions.DryRun = true
+	return p.client.options.Backend.Bump(p.client.repo, increment, bumpOptions)
+}
+
+// Bump resolves the increment with Increment and releases it.
+func (p *Project) Bump(ctx context.Context, increment string, options BumpOptions) (*semver.Version, error) {
+	increment, err := p.Increment(ctx, increment, options)
+	if err != nil {
+		return nil, err
+	}
+	return p.Release(ctx, increment, options)
+}
+
+// Release tags HEAD with the increment without checking the skip hint and the changes.
+func (p *Project) Release(ctx context.Context, increment string, options BumpOptions) (*semver.Version, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.client.options.Backend.Bump(p.client.repo, increment, p.bumpOptions(options))
+}
+
+// Set tags HEAD with the version, which must be greater than the latest release unless AllowLower is set.
+func (p *Project) Set(ctx context.Context, version *semver.Version, options SetOptions) (*semver.Version, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	backend := p.client.options.Backend
+	if err := backend.SkipHintPresent(p.client.repo, p.name); err != nil {
+		return nil, err
+	}
+	bumpOptions := p.bumpOptions(BumpOptions{Tag: options.Tag})
+	bumpOptions.AllowLower = options.AllowLower
+	return backend.Set(p.client.repo, version, bumpOptions)
+}
+
+// Mark tags HEAD with a next version marker, which must be greater than the latest release unless AllowLower
+// is set. Current and Bump use the marker as the next version while it is greater than the increment.
+func (p *Project) Mark(ctx context.Context, version *semver.Version, options MarkOptions) (*semver.Version, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	bumpOptions := p.bumpOptions(BumpOptions{})
+	bumpOptions.AllowLower = options.AllowLower
+	return p.client.options.Backend.MarkNext(p.client.repo, version, bumpOptions)
+}
+
+// Push pushes the tags of the version, the tags of a version group in a single push.
+func (p *Project) Push(ctx context.Context, version *semver.Version) error {
+	if len(p.options.VersionGroup) == 0 {
+		return p.push(ctx, version, p.prefix)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.client.PushTags(ctx, p.Tags(version))
+}
+
+// PushMark pushes the next version marker of the version.
+func (p *Project) PushMark(ctx context.Context, version *semver.Version) error {
+	return p.push(ctx, version, git.MarkerPrefix(p.prefix))
+}
+
+// push pushes the tag of the version with the prefix, with Backend.PushTag when it is set.
+func (p *Project) push(ctx context.Context, version *semver.Version, prefix string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c := p.client.options
+	if c.Backend.PushTag != nil {
+		return c.Backend.PushTag(p.client.repo, version.String(), prefix, c.Remote, c.DryRun,
+			c.Auth.DisableStrictHostChecking, c.Auth.TokenEnvVarKey)
+	}
+	return p.client.PushTags(ctx, []string{prefix + version.String()})
+}
+
+// Check runs the check, the error tells why it failed. The release check returns every failure.
+func (p *Project) Check(ctx context.Context, check Check) (CheckResult, error) {
+	var result CheckResult
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	repo, backend := p.client.repo, p.client.options.Backend
+	switch check {
+	case CheckRelease:
+		var errs errs
+		if err := backend.SkipHintPresent(repo, p.name); err != nil {
+			errs = append(errs, err)
+		}
+		if err := backend.ValidateHEAD(repo, p.client.options.Remote, p.client.options.VersionedBranches); err != nil {
+			errs = append(errs, err)
+		}
+		if len(errs) > 0 {
+			return result, errs
+		}
+		return result, nil
+	case CheckIncrementHint:
+		err := backend.SkipHintPresent(repo, p.name)
+		if errors.Is(err, release.ErrSkipRelease) {
+			result.Skipped = err.Error()
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Increment, err = backend.IncrementHint(repo, p.name)
+		return result, err
+	case CheckChanged:
+		var err error
+		result.Changed, err = p.changed()
+		return result, err
+	default:
+		return result, fmt.Errorf("%w : %s", ErrUnknownCheck, check)
+	}
+}
+
+func (p *Project) bumpOptions(options BumpOptions) bump.Options {
+	c := p.client.options
+	return bump.Options{
+		TagPrefix:            p.prefix,
+		Remote:               c.Remote,
+		VersionedBranches:    c.VersionedBranches,
+		VersionLines:         c.VersionLines,
+		DryRun:               c.DryRun,
+		NearestRelease:       c.NearestRelease,
+		PreReleaseIdentifier: options.PreReleaseIdentifier,
+		InitialVersion:       p.options.InitialVersion,
+		InitialIncrement:     options.InitialIncrement,
+		Channels:             options.Channels,
+		VersionGroup:         p.options.VersionGroup,
+		PreTag:               options.PreTag,
+		TagMessage:           options.Tag.Message,
+		Tagger:               options.Tag.Tagger,
+		Signer:               options.Tag.Signer,
+	}
+}
+
+// changed returns the project files changed since the latest release of the version line of HEAD,
+// release.ErrNoChanges when none did.
+func (p *Project) changed() ([]string, error) {
+	since := plumbing.ZeroHash
+	latest, err := bump.LatestRelease(p.client.repo, p.bumpOptions(BumpOptions{}))
+	switch {
+	case err == nil:
+		since = latest.Ref.Hash()
+	case !errors.Is(err, git.ErrNoTagFound):
+		return nil, err
+	}
+	return release.Changed(p.client.repo, since, release.PathFilter{Paths: p.options.Paths, IgnorePaths: p.options.IgnorePaths})
+}
+
+func (p *Project) conventionalIncrement(options release.ConventionalOptions) (string, error) {
+	since := plumbing.ZeroHash
+	latest, err := bump.LatestRelease(p.client.repo, p.bumpOptions(BumpOptions{}))
+	switch {
+	case err == nil:
+		since = latest.Ref.Hash()
+	case !errors.Is(err, git.ErrNoTagFound) && !errors.Is(err, plumbing.ErrReferenceNotFound):
+		return "", err
+	}
+	return release.ConventionalIncrement(p.client.repo, p.name, since, options)
+}
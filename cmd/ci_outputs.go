@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+	vergo "github.com/sky-uk/vergo/git"
+	"github.com/spf13/cobra"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultDotenvFile is the dotenv report written on GitLab CI, declared in the job as artifacts:reports:dotenv
+const DefaultDotenvFile = "vergo.env"
+
+var invalidEnvChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+type ciFlags struct {
+	outputs    bool
+	dotenvFile string
+}
+
+func addCIFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool(ciOutputs, false, "write the version, tag, previous-version and created outputs to "+
+		"$GITHUB_OUTPUT and $GITHUB_STEP_SUMMARY on GitHub Actions or to --ci-dotenv-file on GitLab CI")
+	cmd.PersistentFlags().String(ciDotenvFile, DefaultDotenvFile, "dotenv report of --ci-outputs on GitLab CI")
+}
+
+func readCIFlags(cmd *cobra.Command) (*ciFlags, error) {
+	outputs, err := cmd.Flags().GetBool(ciOutputs)
+	if err != nil {
+		return nil, err
+	}
+	dotenvFile, err := cmd.Flags().GetString(ciDotenvFile)
+	if err != nil {
+		return nil, err
+	}
+	return &ciFlags{outputs: outputs, dotenvFile: dotenvFile}, nil
+}
+
+// previousRelease returns the highest release of the tag prefix below the version, noRelease when there is none.
+func previousRelease(repo *git.Repository, rootFlags *RootFlags, version *semver.Version) (string, error) {
+	refs, err := vergo.ListRefs(repo, rootFlags.tagPrefix, vergo.DESC, math.MaxInt)
+	if err != nil && !errors.Is(err, vergo.ErrNoTagFound) {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Version.LessThan(version) {
+			return ref.Version.String(), nil
+		}
+	}
+	return noRelease, nil
+}
+
+// ciOutput is a named output of a release, keys are the GitHub Actions output names.
+type ciOutput struct {
+	key, value string
+}
+
+func releaseOutputs(record versionRecord, keyPrefix string) []ciOutput {
+	created := record.Created != nil && *record.Created
+	return []ciOutput{
+		{keyPrefix + "version", record.Version},
+		{keyPrefix + "tag", record.Prefix + record.Version},
+		{keyPrefix + "previous-version", record.Previous},
+		{keyPrefix + "created", strconv.FormatBool(created)},
+	}
+}
+
+// writeCIOutputs writes the outputs of the released versions for GitHub Actions or GitLab CI.
+func writeCIOutputs(flags *ciFlags, records []versionRecord, byProject bool) error {
+	if !flags.outputs {
+		return nil
+	}
+	var outputs []ciOutput
+	for _, record := range records {
+		keyPrefix := ""
+		if byProject {
+			keyPrefix = record.Project + "-"
+		}
+		outputs = append(outputs, releaseOutputs(record, keyPrefix)...)
+	}
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		lines := make([]string, 0, len(outputs))
+		for _, output := range outputs {
+			lines = append(lines, output.key+"="+output.value)
+		}
+		if err := appendCIFile(os.Getenv("GITHUB_OUTPUT"), lines); err != nil {
+			return err
+		}
+		return appendCIFile(os.Getenv("GITHUB_STEP_SUMMARY"), stepSummary(records))
+	case os.Getenv("GITLAB_CI") == "true":
+		lines := make([]string, 0, len(outputs))
+		for _, output := range outputs {
+			name := strings.ToUpper(invalidEnvChars.ReplaceAllString(output.key, "_"))
+			lines = append(lines, envPrefix+name+"="+output.value)
+		}
+		return writeDotenvFile(flags.dotenvFile, lines)
+	default:
+		log.Warnf("No CI environment detected, --%s ignored", ciOutputs)
+		return nil
+	}
+}
+
+func stepSummary(records []versionRecord) []string {
+	lines := []string{"### vergo release", "", "| Tag | Previous version | Version | Created |", "| --- | --- | --- | --- |"}
+	for _, record := range records {
+		created := record.Created != nil && *record.Created
+		lines = append(lines, fmt.Sprintf("| %s%s | %s | %s | %t |", record.Prefix, record.Version, record.Previous,
+			record.Version, created))
+	}
+	return lines
+}
+
+func appendCIFile(file string, lines []string) error {
+	if file == "" {
+		return nil
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeDotenvFile rewrites the dotenv file with the lines, keeping the variables of other lines already in the file.
+func writeDotenvFile(file string, lines []string) error {
+	content, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	replaced := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		replaced[strings.SplitN(line, "=", 2)[0]] = true
+	}
+	var kept []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line != "" && !replaced[strings.SplitN(line, "=", 2)[0]] {
+			kept = append(kept, line)
+		}
+	}
+	return os.WriteFile(file, []byte(strings.Join(append(kept, lines...), "\n")+"\n"), 0644)
+}
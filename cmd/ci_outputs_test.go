@@ -0,0 +1,119 @@
+package cmd_test
+
+import (
+	"github.com/sky-uk/vergo/bump"
+	"github.com/sky-uk/vergo/config"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, file string) string {
+	t.Helper()
+	content, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	return string(content)
+}
+
+func TestBumpShouldWriteGitHubActionsOutputs(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "first")
+	outputDir := t.TempDir()
+	t.Setenv("GITLAB_CI", "")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_OUTPUT", filepath.Join(outputDir, "output"))
+	t.Setenv("GITHUB_STEP_SUMMARY", filepath.Join(outputDir, "summary"))
+
+	for _, increment := range []string{"minor", "minor", "patch"} {
+		DoCommit(t, repo, increment)
+		cmd, _ := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", increment, "--repository-location", tempDir, "-t", "app", "--ci-outputs", "--lightweight-tag"})
+		assert.Nil(t, cmd.Execute())
+	}
+	assert.Equal(t, `version=0.1.0
+tag=app-0.1.0
+previous-version=none
+created=true
+version=0.2.0
+tag=app-0.2.0
+previous-version=0.1.0
+created=true
+version=0.2.1
+tag=app-0.2.1
+previous-version=0.2.0
+created=true
+`, readFile(t, filepath.Join(outputDir, "output")))
+	assert.Contains(t, readFile(t, filepath.Join(outputDir, "summary")), "| app-0.2.1 | 0.2.0 | 0.2.1 | true |\n")
+}
+
+func TestBumpSetShouldTellExistingTagInCIOutputs(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "first")
+	tagHead(t, repo, "app-0.1.0")
+	tagHead(t, repo, "app-0.2.0")
+	output := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITLAB_CI", "")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_OUTPUT", output)
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+
+	cmd, _ := makeBumpFunc(t, bump.Bump)
+	cmd.SetArgs([]string{"bump", "set", "0.2.0", "--repository-location", tempDir, "-t", "app", "--ci-outputs"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "version=0.2.0\ntag=app-0.2.0\nprevious-version=0.1.0\ncreated=false\n", readFile(t, output))
+}
+
+func TestBumpAllShouldWriteGitLabDotenvReport(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(monorepoConfig), 0600))
+	DoCommit(t, repo, "services/api/main.go")
+	DoCommit(t, repo, "services/web/main.go")
+	dotenv := filepath.Join(t.TempDir(), "build.env")
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "true")
+
+	var pushed [][]string
+	cmd, buffer := makeBumpAll(t, &pushed)
+	cmd.SetArgs([]string{"bump", "minor", "--all", "--repository-location", tempDir, "--ci-outputs", "--ci-dotenv-file", dotenv})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "api none -> 0.1.0\nweb none -> 1.0.0", readBuffer(t, buffer))
+	assert.Equal(t, `VERGO_API_VERSION=0.1.0
+VERGO_API_TAG=api-0.1.0
+VERGO_API_PREVIOUS_VERSION=none
+VERGO_API_CREATED=true
+VERGO_WEB_VERSION=1.0.0
+VERGO_WEB_TAG=frontend-1.0.0
+VERGO_WEB_PREVIOUS_VERSION=none
+VERGO_WEB_CREATED=true
+`, readFile(t, dotenv))
+
+	DoCommit(t, repo, "services/web/index.html")
+	cmd, _ = makeBumpAll(t, &pushed)
+	cmd.SetArgs([]string{"bump", "patch", "--all", "--repository-location", tempDir, "--ci-outputs", "--ci-dotenv-file", dotenv})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, `VERGO_API_VERSION=0.1.0
+VERGO_API_TAG=api-0.1.0
+VERGO_API_PREVIOUS_VERSION=none
+VERGO_API_CREATED=true
+VERGO_WEB_VERSION=1.0.1
+VERGO_WEB_TAG=frontend-1.0.1
+VERGO_WEB_PREVIOUS_VERSION=1.0.0
+VERGO_WEB_CREATED=true
+`, readFile(t, dotenv))
+}
+
+func TestCIOutputsShouldBeIgnoredOutsideCI(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "first")
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "")
+	dotenv := filepath.Join(t.TempDir(), "build.env")
+
+	cmd, buffer := makeBumpFunc(t, bump.Bump)
+	cmd.SetArgs([]string{"bump", "minor", "--repository-location", tempDir, "-t", "app", "--ci-outputs", "--ci-dotenv-file", dotenv})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "0.1.0", readBuffer(t, buffer))
+	assert.NoFileExists(t, dotenv)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/sky-uk/vergo/client"
+)
+
+// newClient returns the client of the root flags, the backend holds the functions given to the commands.
+func newClient(repo *git.Repository, rootFlags *RootFlags, backend client.Backend) *client.Client {
+	return client.NewWithRepository(repo, client.Options{
+		Remote:            rootFlags.remote,
+		VersionedBranches: rootFlags.versionedBranches,
+		VersionLines:      rootFlags.versionLines,
+		NearestRelease:    rootFlags.nearestRelease,
+		DryRun:            rootFlags.dryRun,
+		Auth: client.Auth{
+			TokenEnvVarKey:            rootFlags.tokenEnvVarKey,
+			DisableStrictHostChecking: rootFlags.disableStrictHostChecking,
+		},
+		Backend: backend,
+	})
+}
+
+// newProject returns the client project of the root flags.
+func newProject(repo *git.Repository, rootFlags *RootFlags, backend client.Backend) *client.Project {
+	return newClient(repo, rootFlags, backend).Project(rootFlags.tagPrefixRaw, client.ProjectOptions{
+		InitialVersion: rootFlags.initialVersion,
+		Paths:          rootFlags.paths,
+		IgnorePaths:    rootFlags.ignorePaths,
+		VersionGroup:   rootFlags.versionGroup,
+	})
+}
@@ -1,23 +1,41 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	log "github.com/sirupsen/logrus"
 	"github.com/sky-uk/vergo/bump"
+	"github.com/sky-uk/vergo/changelog"
+	"github.com/sky-uk/vergo/client"
 	vergo "github.com/sky-uk/vergo/git"
 	"github.com/sky-uk/vergo/release"
 	"github.com/spf13/cobra"
+	"os"
+	"time"
 )
 
-func BumpCmd(bumpFunc bump.Func, pushTag vergo.PushTagFunc) *cobra.Command {
+// BumpCmd returns the bump command.
+func BumpCmd(bumpFunc bump.Func, setFunc bump.SetFunc, pushTag vergo.PushTagFunc, pushTags vergo.PushTagsFunc) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:       "release (prerelease|patch|minor|major|auto)",
+		Use:       "release (prerelease|patch|minor|major|prepatch|preminor|premajor|release|promote|auto)",
 		Short:     "increments the version numbers",
 		Args:      cobra.ExactValidArgs(1),
-		ValidArgs: []string{"prerelease", "patch", "minor", "major", "auto"},
+		ValidArgs: []string{"prerelease", "patch", "minor", "major", "prepatch", "preminor", "premajor", "release", "promote", "auto"},
 		Aliases:   []string{"bump"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			increment := args[0]
+			all, err := cmd.Flags().GetBool(allProjects)
+			if err != nil {
+				return err
+			}
+			if all {
+				return bumpAll(cmd, increment, bumpFunc, pushTags)
+			}
 			rootFlags, err := readRootFlags(cmd)
 			if err != nil {
 				return err
@@ -26,43 +44,327 @@ func BumpCmd(bumpFunc bump.Func, pushTag vergo.PushTagFunc) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			bumpFlags, err := readBumpFlags(cmd)
+			if err != nil {
+				return err
+			}
 			repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
 			if err != nil {
 				return err
 			}
-			if err := release.SkipHintPresent(repo, rootFlags.tagPrefixRaw); err != nil {
+			existing, err := existingTags(repo)
+			if err != nil {
 				return err
 			}
-			if increment == "auto" {
-				if increment, err = release.IncrementHint(repo, rootFlags.tagPrefixRaw); err != nil {
-					return err
-				}
+			project := newProject(repo, rootFlags, client.Backend{Bump: bumpFunc, PushTag: pushTag, PushTags: pushTags})
+			options, err := bumpOptions(repo, rootFlags, bumpFlags)
+			if err != nil {
+				return err
+			}
+			version, err := project.Bump(cmd.Context(), increment, options)
+			if errors.Is(err, release.ErrNoChanges) {
+				log.WithError(err).Infof("Skipping bump of %s", rootFlags.tagPrefixRaw)
+				return writeOutput(cmd, rootFlags.output, versionRecord{Prefix: rootFlags.tagPrefix, Skipped: err.Error()},
+					func() error { return nil })
 			}
-			version, err := bumpFunc(repo, increment, bump.Options{
-				TagPrefix:         rootFlags.tagPrefix,
-				Remote:            rootFlags.remote,
-				VersionedBranches: rootFlags.versionedBranches,
-				DryRun:            rootFlags.dryRun,
-				NearestRelease:    rootFlags.nearestRelease})
 			if err != nil {
 				return err
 			}
 			if pushTagParam {
-				err = pushTag(repo, version.String(), rootFlags.tagPrefix, rootFlags.remote, rootFlags.dryRun, rootFlags.disableStrictHostChecking, rootFlags.tokenEnvVarKey)
-				if err != nil {
+				if err := project.Push(cmd.Context(), version); err != nil {
 					return err
 				}
 			} else {
 				log.Trace("Push not enabled")
 			}
-			if rootFlags.withPrefix {
-				cmd.Print(rootFlags.tagPrefix, version.String())
+			return writeBumped(cmd, repo, rootFlags, bumpFlags.ci, version, existing)
+		},
+	}
+	cmd.Flags().BoolP(pushTagParam, "u", false, "push the new tag")
+	cmd.Flags().Bool(allProjects, false, "bump every changed project of the config file, or every tag prefix without config, and push the tags in a single push")
+	cmd.Flags().Bool(planOnly, false, "with --all print the planned bumps without tagging")
+	cmd.Flags().String(propagationIncrement, bump.DefaultPropagationIncrement, "with --all the increment of projects whose depends-on projects are released")
+	cmd.Flags().Bool(onlyIfChanged, false, "skip the bump when no file under --paths changed since the latest release")
+	cmd.Flags().Bool(conventionalCommits, false, "auto increment from the conventional commits since the latest release instead of the increment hint")
+	cmd.Flags().String(preReleaseIdentifier, bump.DefaultPreReleaseIdentifier, "identifier used when a pre-release is started e.g. rc gives rc.1")
+	cmd.Flags().StringSlice(preReleaseChannels, nil, "pre-release channels in the form branch-pattern=identifier e.g. develop=beta,feature/*={branch}, "+
+		"pre-release increments on a channel branch use its identifier and do not require a versioned branch")
+	cmd.Flags().Bool(initialIncrement, false, "the first release applies the increment to 0.0.0 e.g. major gives 1.0.0, ignores --initial-version")
+	cmd.Flags().Bool(scopeAsPrefix, false, "only count conventional commits whose scope matches the tag prefix, unscoped commits always count")
+	cmd.Flags().Bool(updateChangelog, false, "insert the changelog of the release, without merge commits, into --changelog-file "+
+		"and tag the commit of the change, cannot be combined with --push-tag as the commit must be pushed with the tag e.g. git push --atomic origin HEAD <tag>")
+	cmd.Flags().String(changelogFile, changelog.DefaultFile, "changelog file relative to the repository root updated with --update-changelog")
+	cmd.Flags().String(changelogTemplate, "", "go template file of the release section written with --update-changelog")
+	addTagFlags(cmd)
+	addCIFlags(cmd)
+	cmd.AddCommand(bumpSetCmd(setFunc, pushTag, pushTags))
+	return cmd
+}
+
+type bumpFlags struct {
+	preReleaseIdentifier, propagationIncrement                   string
+	changelogFile, changelogTemplate                             string
+	channels                                                     []string
+	conventional, scopeAsPrefix, initialIncrement, onlyIfChanged bool
+	updateChangelog                                              bool
+	tag                                                          *tagFlags
+	ci                                                           *ciFlags
+}
+
+func readBumpFlags(cmd *cobra.Command) (*bumpFlags, error) {
+	if err := validateChangelogPush(cmd); err != nil {
+		return nil, err
+	}
+	conventional, err := cmd.Flags().GetBool(conventionalCommits)
+	if err != nil {
+		return nil, err
+	}
+	scopeAsPrefix, err := cmd.Flags().GetBool(scopeAsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	preReleaseIdentifier, err := cmd.Flags().GetString(preReleaseIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	initialIncrement, err := cmd.Flags().GetBool(initialIncrement)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := cmd.Flags().GetStringSlice(preReleaseChannels)
+	if err != nil {
+		return nil, err
+	}
+	onlyIfChanged, err := cmd.Flags().GetBool(onlyIfChanged)
+	if err != nil {
+		return nil, err
+	}
+	propagationIncrement, err := cmd.Flags().GetString(propagationIncrement)
+	if err != nil {
+		return nil, err
+	}
+	updateChangelog, err := cmd.Flags().GetBool(updateChangelog)
+	if err != nil {
+		return nil, err
+	}
+	changelogFile, err := cmd.Flags().GetString(changelogFile)
+	if err != nil {
+		return nil, err
+	}
+	changelogTemplate, err := cmd.Flags().GetString(changelogTemplate)
+	if err != nil {
+		return nil, err
+	}
+	tagFlags, err := readTagFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	ciFlags, err := readCIFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return &bumpFlags{
+		tag:                  tagFlags,
+		ci:                   ciFlags,
+		propagationIncrement: propagationIncrement,
+		changelogFile:        changelogFile,
+		changelogTemplate:    changelogTemplate,
+		updateChangelog:      updateChangelog,
+		preReleaseIdentifier: preReleaseIdentifier,
+		channels:             channels,
+		conventional:         conventional,
+		scopeAsPrefix:        scopeAsPrefix,
+		initialIncrement:     initialIncrement,
+		onlyIfChanged:        onlyIfChanged,
+	}, nil
+}
+
+// validateChangelogPush refuses --update-changelog with --push-tag, which would push a tag of an unpushed commit.
+func validateChangelogPush(cmd *cobra.Command) error {
+	update, err := cmd.Flags().GetBool(updateChangelog)
+	if err != nil {
+		return err
+	}
+	push, err := cmd.Flags().GetBool(pushTagParam)
+	if err != nil {
+		return err
+	}
+	if update && push {
+		return fmt.Errorf("%w : --%s cannot be combined with --%s", ErrInvalidArg, updateChangelog, pushTagParam)
+	}
+	return nil
+}
+
+func bumpOptions(repo *git.Repository, rootFlags *RootFlags, bumpFlags *bumpFlags) (client.BumpOptions, error) {
+	options := client.BumpOptions{
+		PreReleaseIdentifier: bumpFlags.preReleaseIdentifier,
+		Channels:             bumpFlags.channels,
+		InitialIncrement:     bumpFlags.initialIncrement,
+		ConventionalCommits:  bumpFlags.conventional,
+		ScopeAsPrefix:        bumpFlags.scopeAsPrefix,
+		OnlyIfChanged:        bumpFlags.onlyIfChanged,
+	}
+	var err error
+	if options.Tag, err = annotate(repo, rootFlags, bumpFlags.tag); err != nil {
+		return options, err
+	}
+	if bumpFlags.updateChangelog {
+		options.PreTag = func(version semver.Version) error {
+			return updateChangelogFile(repo, version, rootFlags, bumpFlags)
+		}
+	}
+	return options, nil
+}
+
+// releaseChangelog returns the changelog of the version from the latest release to HEAD, dated now.
+func releaseChangelog(repo *git.Repository, version semver.Version, rootFlags *RootFlags) (*changelog.Changelog, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	since, from := plumbing.ZeroHash, ""
+	latest, err := latestRef(repo, rootFlags)
+	switch {
+	case err == nil:
+		since, from = latest.Ref.Hash(), latest.Version.String()
+	case !errors.Is(err, vergo.ErrNoTagFound):
+		return nil, err
+	}
+	releaseLog, err := changelog.Generate(repo, since, head.Hash(), changelog.Options{
+		Filter: release.PathFilter{Paths: rootFlags.paths, IgnorePaths: rootFlags.ignorePaths},
+	})
+	if err != nil {
+		return nil, err
+	}
+	releaseLog.TagPrefix, releaseLog.From, releaseLog.To, releaseLog.Date = rootFlags.tagPrefix, from, version.String(), time.Now()
+	return releaseLog, nil
+}
+
+// updateChangelogFile inserts the release into the changelog file and commits it, the dry run logs the diff.
+func updateChangelogFile(repo *git.Repository, version semver.Version, rootFlags *RootFlags, bumpFlags *bumpFlags) error {
+	releaseLog, err := releaseChangelog(repo, version, rootFlags)
+	if err != nil {
+		return err
+	}
+	var section bytes.Buffer
+	if bumpFlags.changelogTemplate != "" {
+		err = changelog.RenderTemplateFile(&section, releaseLog, bumpFlags.changelogTemplate)
+	} else {
+		err = changelog.RenderTemplate(&section, releaseLog, changelog.FileTemplate)
+	}
+	if err != nil {
+		return err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	content, err := util.ReadFile(worktree.Filesystem, bumpFlags.changelogFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	updated := changelog.Insert(string(content), section.String())
+	if rootFlags.dryRun {
+		log.Infof("Dry run: update %s\n%s", bumpFlags.changelogFile, changelog.Diff(bumpFlags.changelogFile, string(content), updated))
+		return nil
+	}
+	if err := util.WriteFile(worktree.Filesystem, bumpFlags.changelogFile, []byte(updated), 0644); err != nil {
+		return err
+	}
+	return vergo.CommitFiles(repo, "chore(release): "+rootFlags.tagPrefix+version.String(), bumpFlags.changelogFile)
+}
+
+func bumpSetCmd(setFunc bump.SetFunc, pushTag vergo.PushTagFunc, pushTags vergo.PushTagsFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <version>",
+		Short: "tags HEAD with an explicit version greater than the latest release",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := semver.NewVersion(args[0])
+			if err != nil {
+				return fmt.Errorf("%w : %s", ErrInvalidArg, args[0])
+			}
+			rootFlags, err := readRootFlags(cmd)
+			if err != nil {
+				return err
+			}
+			pushTagParam, err := cmd.Flags().GetBool(pushTagParam)
+			if err != nil {
+				return err
+			}
+			allowLower, err := cmd.Flags().GetBool(allowLower)
+			if err != nil {
+				return err
+			}
+			repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
+			if err != nil {
+				return err
+			}
+			tagFlags, err := readTagFlags(cmd)
+			if err != nil {
+				return err
+			}
+			ciFlags, err := readCIFlags(cmd)
+			if err != nil {
+				return err
+			}
+			tag, err := annotate(repo, rootFlags, tagFlags)
+			if err != nil {
+				return err
+			}
+			existing, err := existingTags(repo)
+			if err != nil {
+				return err
+			}
+			project := newProject(repo, rootFlags, client.Backend{Set: setFunc, PushTag: pushTag, PushTags: pushTags})
+			version, err = project.Set(cmd.Context(), version, client.SetOptions{AllowLower: allowLower, Tag: tag})
+			if err != nil {
+				return err
+			}
+			if pushTagParam {
+				if err := project.Push(cmd.Context(), version); err != nil {
+					return err
+				}
 			} else {
-				cmd.Print(version.String())
+				log.Trace("Push not enabled")
 			}
-			return nil
+			return writeBumped(cmd, repo, rootFlags, ciFlags, version, existing)
 		},
 	}
 	cmd.Flags().BoolP(pushTagParam, "u", false, "push the new tag")
+	cmd.Flags().Bool(allowLower, false, "allow a version which is not greater than the latest release")
 	return cmd
 }
+
+// writeBumped prints the version returned by bump and writes the CI outputs.
+func writeBumped(cmd *cobra.Command, repo *git.Repository, rootFlags *RootFlags, ciFlags *ciFlags, version *semver.Version,
+	existing map[string]bool) error {
+	record := versionRecord{Version: version.String(), Prefix: rootFlags.tagPrefix}
+	var err error
+	if rootFlags.output != textOutput || ciFlags.outputs {
+		if record, err = bumpedRecord(repo, rootFlags, version, existing); err != nil {
+			return err
+		}
+		if err := writeCIOutputs(ciFlags, []versionRecord{record}, false); err != nil {
+			return err
+		}
+	} else if describeVersions(rootFlags) {
+		if record, err = headVersionRecord(repo, rootFlags.tagPrefix, version); err != nil {
+			return err
+		}
+	}
+	return writeOutput(cmd, rootFlags.output, record, func() error {
+		tmpl, err := versionTemplate(rootFlags)
+		if err != nil {
+			return err
+		}
+		return printVersion(cmd, repo, rootFlags, tmpl, version, record)
+	})
+}
+
+func latestRef(repo *git.Repository, rootFlags *RootFlags) (vergo.SemverRef, error) {
+	if rootFlags.nearestRelease {
+		return vergo.NearestTag(repo, rootFlags.tagPrefix)
+	}
+	return vergo.LatestRef(repo, rootFlags.tagPrefix)
+}
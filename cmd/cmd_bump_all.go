@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/sky-uk/vergo/bump"
+	"github.com/sky-uk/vergo/client"
+	"github.com/sky-uk/vergo/config"
+	vergo "github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/release"
+	"github.com/spf13/cobra"
+	"path"
+	"strings"
+)
+
+const noRelease = "none"
+
+// bumpTarget is a project of the config file or a tag prefix found among the tags.
+type bumpTarget struct {
+	name, tagPrefixRaw string
+	paths, dependsOn   []string
+}
+
+// plannedTarget is a target with its flags, project and direct increment or the reason it is skipped.
+type plannedTarget struct {
+	rootFlags         *RootFlags
+	bumpFlags         *bumpFlags
+	project           *client.Project
+	options           client.BumpOptions
+	previous, skipped string
+	increment         string
+}
+
+type bumpResult struct {
+	name, previous, next, skipped, cause string
+}
+
+func (r bumpResult) String() string {
+	switch {
+	case r.skipped != "":
+		return fmt.Sprintf("%s %s %s", r.name, r.previous, r.skipped)
+	case r.cause != "":
+		return fmt.Sprintf("%s %s -> %s (depends on %s)", r.name, r.previous, r.next, r.cause)
+	default:
+		return fmt.Sprintf("%s %s -> %s", r.name, r.previous, r.next)
+	}
+}
+
+// bumpAll bumps every changed project and its dependants, the tags are deleted when a release or the push fails.
+func bumpAll(cmd *cobra.Command, increment string, bumpFunc bump.Func, pushTags vergo.PushTagsFunc) error {
+	if cmd.Flags().Changed(tagPrefix) || cmd.Flags().Changed(project) {
+		return fmt.Errorf("%w : --%s cannot be combined with --%s or --%s", ErrInvalidArg, allProjects, tagPrefix, project)
+	}
+	rootFlags, err := readRootFlags(cmd)
+	if err != nil {
+		return err
+	}
+	pushTagParam, err := cmd.Flags().GetBool(pushTagParam)
+	if err != nil {
+		return err
+	}
+	planOnly, err := cmd.Flags().GetBool(planOnly)
+	if err != nil {
+		return err
+	}
+	repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return err
+	}
+	repoConfig, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	targets, err := bumpTargets(repo, repoConfig)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(targets))
+	planned := make(map[string]*plannedTarget, len(targets))
+	dependsOn := make(map[string][]string)
+	increments := make(map[string]string)
+	propagation := make(map[string]string)
+	for _, target := range targets {
+		plan, err := planTarget(cmd, repo, increment, repoConfig, target, bumpFunc)
+		if err != nil {
+			return err
+		}
+		names = append(names, target.name)
+		planned[target.name] = plan
+		dependsOn[target.name] = target.dependsOn
+		increments[target.name] = plan.increment
+		propagation[target.name] = plan.bumpFlags.propagationIncrement
+	}
+	steps, err := bump.Plan(names, dependsOn, increments, propagation)
+	if err != nil {
+		return err
+	}
+	if planOnly {
+		lines := make([]string, 0, len(steps))
+		for _, step := range steps {
+			lines = append(lines, step.String())
+		}
+		cmd.Print(strings.Join(lines, "\n"))
+		return nil
+	}
+
+	existing, err := existingTags(repo)
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		plan := planned[step.Project]
+		if _, err := plan.project.Preview(cmd.Context(), step.Increment, plan.options); err != nil {
+			return fmt.Errorf("%w : %s", err, step.Project)
+		}
+	}
+	var tags []string
+	queued := make(map[string]bool)
+	results := make(map[string]bumpResult, len(targets))
+	records := make(map[string]versionRecord, len(targets))
+	var released []versionRecord
+	for _, step := range steps {
+		plan := planned[step.Project]
+		version, err := plan.project.Release(cmd.Context(), step.Increment, plan.options)
+		if err != nil {
+			return rollbackTags(repo, tags, existing, fmt.Errorf("%w : %s", err, step.Project))
+		}
+		for _, tag := range plan.project.Tags(version) {
+			if !queued[tag] {
+				queued[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		results[step.Project] = bumpResult{name: step.Project, previous: plan.previous, next: version.String(), cause: step.Cause}
+		if rootFlags.output != textOutput || plan.bumpFlags.ci.outputs {
+			record, err := bumpedRecord(repo, plan.rootFlags, version, existing)
+			if err != nil {
+				return err
+			}
+			record.Project, record.Cause = step.Project, step.Cause
+			records[step.Project] = record
+			released = append(released, record)
+		}
+	}
+
+	switch {
+	case !pushTagParam:
+		log.Trace("Push not enabled")
+	case len(tags) == 0:
+		log.Info("No tag to push")
+	default:
+		err = newClient(repo, rootFlags, client.Backend{PushTags: pushTags}).PushTags(cmd.Context(), tags)
+		if err != nil {
+			return rollbackTags(repo, tags, existing, err)
+		}
+	}
+	ciFlags, err := readCIFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if err := writeCIOutputs(ciFlags, released, true); err != nil {
+		return err
+	}
+	lines := make([]string, 0, len(targets))
+	summary := make([]versionRecord, 0, len(targets))
+	for _, target := range targets {
+		result, ok := results[target.name]
+		record := records[target.name]
+		if !ok {
+			plan := planned[target.name]
+			result = bumpResult{name: target.name, previous: plan.previous, skipped: plan.skipped}
+			record = versionRecord{Project: target.name, Prefix: plan.rootFlags.tagPrefix, Previous: plan.previous, Skipped: plan.skipped}
+		}
+		lines = append(lines, result.String())
+		summary = append(summary, record)
+	}
+	return writeOutput(cmd, rootFlags.output, summary, func() error {
+		cmd.Print(strings.Join(lines, "\n"))
+		return nil
+	})
+}
+
+// rollbackTags deletes the tags created by bump --all and returns err.
+func rollbackTags(repo *git.Repository, tags []string, existing map[string]bool, err error) error {
+	for _, tag := range tags {
+		if existing[tag] {
+			continue
+		}
+		switch deleteErr := repo.DeleteTag(tag); {
+		case deleteErr == nil:
+			log.Infof("Deleted tag %s", tag)
+		case !errors.Is(deleteErr, git.ErrTagNotFound):
+			log.WithError(deleteErr).Errorf("Could not delete tag %s", tag)
+		}
+	}
+	return err
+}
+
+func bumpTargets(repo *git.Repository, repoConfig *config.Config) ([]bumpTarget, error) {
+	var targets []bumpTarget
+	if repoConfig != nil && len(repoConfig.Projects) > 0 {
+		for _, name := range repoConfig.ProjectNames() {
+			targets = append(targets, bumpTarget{name: name, tagPrefixRaw: repoConfig.TagPrefix(name),
+				paths: repoConfig.Projects[name].Paths})
+		}
+		for i, target := range targets {
+			for _, dependency := range repoConfig.Projects[target.name].DependsOn {
+				name, err := targetName(targets, dependency)
+				if err != nil {
+					return nil, err
+				}
+				targets[i].dependsOn = append(targets[i].dependsOn, name)
+			}
+		}
+		return targets, nil
+	}
+	prefixes, err := vergo.TagPrefixes(repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, prefix := range prefixes {
+		targets = append(targets, bumpTarget{name: strings.TrimSuffix(prefix, "-"), tagPrefixRaw: rawTagPrefix(prefix)})
+	}
+	return targets, nil
+}
+
+// targetName resolves a dependency given by project name, tag prefix or a path of the project, unknown
+// dependencies are returned as they are.
+func targetName(targets []bumpTarget, dependency string) (string, error) {
+	for _, target := range targets {
+		if target.name == dependency {
+			return dependency, nil
+		}
+	}
+	for _, target := range targets {
+		if target.tagPrefixRaw == dependency {
+			return target.name, nil
+		}
+	}
+	dependencyPath := path.Clean(dependency)
+	for _, target := range targets {
+		if len(target.paths) == 0 {
+			continue
+		}
+		match, err := release.PathFilter{Paths: target.paths}.Match(dependencyPath)
+		if err != nil {
+			return "", err
+		}
+		if match {
+			return target.name, nil
+		}
+	}
+	return dependency, nil
+}
+
+// planTarget reads the flags of the target and its direct increment, empty when the project is skipped.
+func planTarget(cmd *cobra.Command, repo *git.Repository, increment string, repoConfig *config.Config,
+	target bumpTarget, bumpFunc bump.Func) (*plannedTarget, error) {
+	if repoConfig != nil {
+		if err := applyProject(cmd, repoConfig, projectOf(repoConfig, target)); err != nil {
+			return nil, err
+		}
+	}
+	rootFlags, err := parseRootFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	rootFlags.tagPrefixRaw = target.tagPrefixRaw
+	rootFlags.tagPrefix = sanitiseTagPrefix(target.tagPrefixRaw)
+	bumpFlags, err := readBumpFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	bumpFlags.onlyIfChanged = true
+	options, err := bumpOptions(repo, rootFlags, bumpFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &plannedTarget{
+		rootFlags: rootFlags,
+		bumpFlags: bumpFlags,
+		project:   newProject(repo, rootFlags, client.Backend{Bump: bumpFunc}),
+		options:   options,
+		previous:  noRelease,
+	}
+	latest, err := latestRef(repo, rootFlags)
+	switch {
+	case err == nil:
+		plan.previous = latest.Version.String()
+	case !errors.Is(err, vergo.ErrNoTagFound):
+		return nil, err
+	}
+	plan.increment, err = plan.project.Increment(cmd.Context(), increment, options)
+	switch {
+	case errors.Is(err, release.ErrNoChanges):
+		plan.skipped = "unchanged"
+	case errors.Is(err, release.ErrSkipRelease):
+		plan.skipped = "skipped by hint"
+	case errors.Is(err, release.ErrNoIncrement):
+		plan.skipped = "no increment"
+	case err != nil:
+		return nil, fmt.Errorf("%w : %s", err, target.name)
+	default:
+		return plan, nil
+	}
+	log.Infof("No direct bump of %s: %s", target.name, plan.skipped)
+	return plan, nil
+}
+
+// projectOf returns the config project of the target, empty for a tag prefix found among the tags.
+func projectOf(repoConfig *config.Config, target bumpTarget) string {
+	if _, ok := repoConfig.Projects[target.name]; ok {
+		return target.name
+	}
+	return ""
+}
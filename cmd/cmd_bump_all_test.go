@@ -0,0 +1,257 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sky-uk/vergo/bump"
+	. "github.com/sky-uk/vergo/cmd"
+	"github.com/sky-uk/vergo/config"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func makeBumpAll(t *testing.T, pushed *[][]string) (*cobra.Command, *bytes.Buffer) {
+	t.Helper()
+	pushTags := func(_ *git.Repository, tags []string, _ string, _ bool, _ bool, _ string) error {
+		*pushed = append(*pushed, tags)
+		return nil
+	}
+	cmd := RootCmd()
+	cmd.AddCommand(BumpCmd(bump.Bump, bump.Set, mockPushTagFailure, pushTags))
+	b := bytes.NewBufferString("")
+	cmd.SetOut(b)
+	cmd.SetErr(io.Discard)
+	return cmd, b
+}
+
+const monorepoConfig = `
+projects:
+  api:
+    paths: [services/api]
+  web:
+    tag-prefix: frontend
+    paths: [services/web]
+    initial-version: 1.0.0
+`
+
+func TestBumpAllShouldBumpChangedConfigProjects(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(monorepoConfig), 0600))
+	DoCommit(t, repo, "services/api/main.go")
+	DoCommit(t, repo, "services/web/main.go")
+	var pushed [][]string
+	{
+		cmd, buffer := makeBumpAll(t, &pushed)
+		cmd.SetArgs([]string{"bump", "minor", "--all", "--push-tag", "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "api none -> 0.1.0\nweb none -> 1.0.0", readBuffer(t, buffer))
+		assert.Equal(t, [][]string{{"api-0.1.0", "frontend-1.0.0"}}, pushed)
+	}
+	DoCommit(t, repo, "services/web/index.html")
+	{
+		cmd, buffer := makeBumpAll(t, &pushed)
+		cmd.SetArgs([]string{"bump", "patch", "--all", "--push-tag", "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "api 0.1.0 unchanged\nweb 1.0.0 -> 1.0.1", readBuffer(t, buffer))
+		assert.Equal(t, []string{"frontend-1.0.1"}, pushed[1])
+	}
+	{
+		cmd, _ := makeBumpAll(t, &pushed)
+		cmd.SetArgs([]string{"bump", "patch", "--all", "-t", "api", "--repository-location", tempDir})
+		assert.ErrorIs(t, cmd.Execute(), ErrInvalidArg)
+	}
+}
+
+func TestBumpShouldDefaultProjectTagPrefixToItsName(t *testing.T) {
+	bumpAndRead := func(args ...string) string {
+		repo, tempDir := PersistentRepository(t)
+		assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(monorepoConfig), 0600))
+		DoCommit(t, repo, "services/api/main.go")
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs(append([]string{"bump", "minor", "--repository-location", tempDir}, args...))
+		assert.Nil(t, cmd.Execute())
+		return readBuffer(t, buffer)
+	}
+	assert.Equal(t, "api-0.1.0", bumpAndRead("--project", "api", "-p"))
+	assert.Equal(t, "api-0.1.0", bumpAndRead("-t", "api", "-p"))
+
+	repo, tempDir := PersistentRepository(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(monorepoConfig), 0600))
+	DoCommit(t, repo, "services/api/main.go")
+	var pushed [][]string
+	cmd, _ := makeBumpAll(t, &pushed)
+	cmd.SetArgs([]string{"bump", "minor", "--all", "--push-tag", "--repository-location", tempDir})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, [][]string{{"api-0.1.0", "frontend-1.0.0"}}, pushed)
+}
+
+func TestBumpAllShouldBumpTagPrefixesWithoutConfig(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "init")
+	for _, prefix := range []string{"api", "web"} {
+		cmd, _ := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", "minor", "-t", prefix, "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+	}
+	DoCommitWithMessage(t, repo, "foo", "fix [vergo:web:patch-release]")
+	var pushed [][]string
+	cmd, buffer := makeBumpAll(t, &pushed)
+	cmd.SetArgs([]string{"bump", "auto", "--all", "--repository-location", tempDir})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "api 0.1.0 no increment\nweb 0.1.0 -> 0.1.1", readBuffer(t, buffer))
+	assert.Empty(t, pushed)
+}
+
+const dependentConfig = `
+projects:
+  core:
+    paths: [libs/core]
+  http:
+    paths: [libs/http]
+    depends-on: [core]
+  app:
+    tag-prefix: application
+    paths: [services/app]
+    depends-on: [http]
+    propagation-increment: minor
+`
+
+func TestBumpAllShouldCascadeToDependants(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(dependentConfig), 0600))
+	DoCommit(t, repo, "libs/core/core.go")
+	DoCommit(t, repo, "libs/http/http.go")
+	DoCommit(t, repo, "services/app/main.go")
+	var pushed [][]string
+	{
+		cmd, _ := makeBumpAll(t, &pushed)
+		cmd.SetArgs([]string{"bump", "minor", "--all", "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+	}
+	DoCommit(t, repo, "libs/core/core_test.go")
+	{
+		cmd, buffer := makeBumpAll(t, &pushed)
+		cmd.SetArgs([]string{"bump", "patch", "--all", "--plan", "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "core patch\nhttp patch (depends on core)\napp minor (depends on http)", readBuffer(t, buffer))
+	}
+	{
+		cmd, buffer := makeBumpAll(t, &pushed)
+		cmd.SetArgs([]string{"bump", "patch", "--all", "--push-tag", "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "app 0.1.0 -> 0.2.0 (depends on http)\ncore 0.1.0 -> 0.1.1\nhttp 0.1.0 -> 0.1.1 (depends on core)",
+			readBuffer(t, buffer))
+		assert.Equal(t, [][]string{{"core-0.1.1", "http-0.1.1", "application-0.2.0"}}, pushed)
+	}
+}
+
+func TestBumpAllShouldResolveDependenciesByPath(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	pathConfig := strings.ReplaceAll(strings.ReplaceAll(dependentConfig, "depends-on: [core]", "depends-on: [./libs/core/]"),
+		"depends-on: [http]", "depends-on: [libs/http/http.go]")
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(pathConfig), 0600))
+	DoCommit(t, repo, "libs/core/core.go")
+	DoCommit(t, repo, "libs/http/http.go")
+	DoCommit(t, repo, "services/app/main.go")
+	var pushed [][]string
+	{
+		cmd, _ := makeBumpAll(t, &pushed)
+		cmd.SetArgs([]string{"bump", "minor", "--all", "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+	}
+	DoCommit(t, repo, "libs/core/core_test.go")
+	cmd, buffer := makeBumpAll(t, &pushed)
+	cmd.SetArgs([]string{"bump", "patch", "--all", "--plan", "--repository-location", tempDir})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "core patch\nhttp patch (depends on core)\napp minor (depends on http)", readBuffer(t, buffer))
+}
+
+func TestBumpShouldPushEveryMemberOfVersionGroup(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "init")
+	var pushed [][]string
+	cmd, buffer := makeBumpAll(t, &pushed)
+	cmd.SetArgs([]string{"bump", "minor", "-t", "sdk-go", "--version-group", "sdk-java,sdk-py", "--push-tag", "--repository-location", tempDir})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "0.1.0", readBuffer(t, buffer))
+	assert.Equal(t, [][]string{{"sdk-go-0.1.0", "sdk-java-0.1.0", "sdk-py-0.1.0"}}, pushed)
+}
+
+func TestBumpAllShouldDeleteCreatedTagsWhenPushFails(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(monorepoConfig), 0600))
+	DoCommit(t, repo, "services/api/main.go")
+	DoCommit(t, repo, "services/web/index.html")
+	pushTags := func(_ *git.Repository, _ []string, _ string, _ bool, _ bool, _ string) error {
+		return errors.New("push tags failed")
+	}
+	cmd := RootCmd()
+	cmd.AddCommand(BumpCmd(bump.Bump, bump.Set, mockPushTagFailure, pushTags))
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"bump", "minor", "--all", "--push-tag", "--repository-location", tempDir})
+	assert.EqualError(t, cmd.Execute(), "push tags failed")
+	_, err := repo.Tag("api-0.1.0")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+	_, err = repo.Tag("frontend-1.0.0")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+}
+
+func TestBumpAllShouldDeleteCreatedTagsWhenReleaseFails(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(monorepoConfig), 0600))
+	DoCommit(t, repo, "services/api/main.go")
+	DoCommit(t, repo, "services/web/index.html")
+	tagHead(t, repo, "tool-1.0.0")
+	bumpFunc := func(repo *git.Repository, increment string, options bump.Options) (*semver.Version, error) {
+		if options.TagPrefix == "frontend-" && !options.DryRun {
+			return nil, errors.New("release failed")
+		}
+		return bump.Bump(repo, increment, options)
+	}
+	var pushed [][]string
+	cmd := RootCmd()
+	cmd.AddCommand(BumpCmd(bumpFunc, bump.Set, mockPushTagFailure, func(_ *git.Repository, tags []string, _ string, _ bool, _ bool, _ string) error {
+		pushed = append(pushed, tags)
+		return nil
+	}))
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"bump", "minor", "--all", "--push-tag", "--repository-location", tempDir})
+	assert.EqualError(t, cmd.Execute(), "release failed : web")
+	_, err := repo.Tag("api-0.1.0")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+	_, err = repo.Tag("tool-1.0.0")
+	assert.Nil(t, err)
+	assert.Empty(t, pushed)
+}
+
+func TestBumpAllShouldNotTagWhenAnyReleaseIsInvalid(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(monorepoConfig+"    versioned-branch-names: [main]\n"), 0600))
+	DoCommit(t, repo, "services/api/main.go")
+	DoCommit(t, repo, "services/web/index.html")
+	cmd := RootCmd()
+	cmd.AddCommand(BumpCmd(bump.Bump, bump.Set, mockPushTagFailure, mockPushTagsSuccess))
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"bump", "minor", "--all", "--repository-location", tempDir})
+	err := cmd.Execute()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is not in versioned branches list")
+	tags, err := repo.Tags()
+	assert.Nil(t, err)
+	assert.Nil(t, tags.ForEach(func(ref *plumbing.Reference) error {
+		t.Errorf("unexpected tag %s", ref.Name().Short())
+		return nil
+	}))
+}
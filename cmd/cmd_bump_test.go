@@ -2,10 +2,15 @@ package cmd_test
 
 import (
 	"fmt"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/sky-uk/vergo/bump"
+	. "github.com/sky-uk/vergo/cmd"
 	. "github.com/sky-uk/vergo/internal-test"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -30,7 +35,7 @@ func TestBumpShouldWorkWithIncrements(t *testing.T) {
 
 //nolint:scopelint,paralleltest
 func TestBumpShouldWorkWithAutoIncrement(t *testing.T) {
-	nextVersion := []string{"0.1.0-alpha1", "0.1.1", "0.2.0", "1.0.0"}
+	nextVersion := []string{"0.1.1-alpha1", "0.1.1", "0.2.0", "1.0.0"}
 	for _, prefix := range prefixes {
 		for i, increment := range increments {
 			t.Run(prefix+"-"+increment, func(t *testing.T) {
@@ -121,3 +126,113 @@ func TestBumpDetectDotGit(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "0.1.0", readBuffer(t, buffer))
 }
+
+//nolint:scopelint,paralleltest
+func TestBumpShouldWorkWithConventionalCommits(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "init")
+	{
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", "minor", "--repository-location", tempDir, "-t", "app"})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "0.1.0", readBuffer(t, buffer))
+	}
+	DoCommitWithMessage(t, repo, "some file", "feat(other): a feature for another prefix")
+	DoCommitWithMessage(t, repo, "another file", "fix: a fix")
+	{
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", "auto", "--repository-location", tempDir, "-t", "app", "--conventional-commits", "--scope-as-prefix"})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "0.1.1", readBuffer(t, buffer))
+	}
+}
+
+func TestBumpSetShouldTagExplicitVersion(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "init")
+	{
+		cmd, buffer := makeBump(t)
+		cmd.SetArgs([]string{"bump", "set", "2.0.0", "--repository-location", tempDir, "-t", "app", "-p"})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "app-2.0.0", readBuffer(t, buffer))
+	}
+	DoCommit(t, repo, "another")
+	{
+		cmd, _ := makeBump(t)
+		cmd.SetArgs([]string{"bump", "set", "1.0.0", "--repository-location", tempDir, "-t", "app"})
+		assert.ErrorIs(t, cmd.Execute(), bump.ErrVersionNotGreater)
+	}
+	{
+		cmd, _ := makeBump(t)
+		cmd.SetArgs([]string{"bump", "set", "not-a-version", "--repository-location", tempDir, "-t", "app"})
+		assert.ErrorIs(t, cmd.Execute(), ErrInvalidArg)
+	}
+}
+
+func TestBumpShouldUseInitialVersion(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "init")
+	cmd, buffer := makeBumpFunc(t, bump.Bump)
+	cmd.SetArgs([]string{"bump", "major", "--repository-location", tempDir, "-t", "app", "--initial-increment"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "1.0.0", readBuffer(t, buffer))
+}
+
+func TestBumpShouldUsePreReleaseChannel(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "init")
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+	assert.Nil(t, worktree.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("develop"), Create: true}))
+	cmd, buffer := makeBumpFunc(t, bump.Bump)
+	cmd.SetArgs([]string{"bump", "prerelease", "--repository-location", tempDir, "-t", "app", "--pre-release-channels", "develop=beta"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "0.1.0-beta.1", readBuffer(t, buffer))
+}
+
+func TestBumpShouldCreateAnnotatedTags(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommitWithMessage(t, repo, "a", "feat: orders")
+	t.Setenv("GIT_COMMITTER_NAME", "Release Bot")
+	t.Setenv("GIT_COMMITTER_EMAIL", "release@example.com")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "sky-uk/vergo")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	{
+		cmd, _ := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", "minor", "--repository-location", tempDir, "-t", "app",
+			"--message", "{{ .Tag }} built by {{ .BuildURL }}\n\n{{ .Changelog }}"})
+		assert.Nil(t, cmd.Execute())
+		tag, err := repo.Tag("app-0.1.0")
+		assert.Nil(t, err)
+		tagObject, err := repo.TagObject(tag.Hash())
+		assert.Nil(t, err)
+		assert.Equal(t, "Release Bot", tagObject.Tagger.Name)
+		assert.Equal(t, "release@example.com", tagObject.Tagger.Email)
+		assert.Regexp(t, `^app-0\.1\.0 built by https://github.com/sky-uk/vergo/actions/runs/42\n\n## \[0\.1\.0\] - .*\n\n### Features\n\n- orders`,
+			tagObject.Message)
+	}
+	DoCommit(t, repo, "b")
+	{
+		cmd, _ := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", "minor", "--repository-location", tempDir, "-t", "app", "--lightweight-tag"})
+		assert.Nil(t, cmd.Execute())
+		tag, err := repo.Tag("app-0.2.0")
+		assert.Nil(t, err)
+		_, err = repo.TagObject(tag.Hash())
+		assert.ErrorIs(t, err, plumbing.ErrObjectNotFound)
+	}
+	DoCommit(t, repo, "c")
+	{
+		messageFile := filepath.Join(t.TempDir(), "message.tmpl")
+		assert.Nil(t, os.WriteFile(messageFile, []byte("Version {{ .Version }}"), 0600))
+		cmd, _ := makeBump(t)
+		cmd.SetArgs([]string{"bump", "set", "1.0.0", "--repository-location", tempDir, "-t", "app", "--message-file", messageFile})
+		assert.Nil(t, cmd.Execute())
+		tag, err := repo.Tag("app-1.0.0")
+		assert.Nil(t, err)
+		tagObject, err := repo.TagObject(tag.Hash())
+		assert.Nil(t, err)
+		assert.Equal(t, "Version 1.0.0", strings.TrimSpace(tagObject.Message))
+	}
+}
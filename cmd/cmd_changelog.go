@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	log "github.com/sirupsen/logrus"
+	"github.com/sky-uk/vergo/changelog"
+	vergo "github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/release"
+	"github.com/spf13/cobra"
+	"math"
+	"strings"
+)
+
+const headRevision = "HEAD"
+
+func ChangelogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "lists the commits of a release grouped by conventional commit type",
+		Long: "lists the commits between the previous release and --to, by default the latest release, " +
+			"--to HEAD lists the unreleased commits",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootFlags, err := readRootFlags(cmd)
+			if err != nil {
+				return err
+			}
+			from, err := cmd.Flags().GetString(changelogFrom)
+			if err != nil {
+				return err
+			}
+			to, err := cmd.Flags().GetString(changelogTo)
+			if err != nil {
+				return err
+			}
+			includeMerges, err := cmd.Flags().GetBool(changelogIncludeMerges)
+			if err != nil {
+				return err
+			}
+			format, template, err := readChangelogFlags(cmd)
+			if err != nil {
+				return err
+			}
+			repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
+			if err != nil {
+				return err
+			}
+			bounds, err := changelogRange(repo, rootFlags, from, to)
+			if err != nil {
+				return err
+			}
+			releaseLog, err := changelog.Generate(repo, bounds.since, bounds.until, changelog.Options{
+				Filter:        release.PathFilter{Paths: rootFlags.paths, IgnorePaths: rootFlags.ignorePaths},
+				IncludeMerges: includeMerges,
+			})
+			if err != nil {
+				return err
+			}
+			releaseLog.TagPrefix, releaseLog.From, releaseLog.To = rootFlags.tagPrefix, bounds.from, bounds.to
+			return renderChangelog(cmd, releaseLog, format, template)
+		},
+	}
+	cmd.Flags().String(changelogFrom, "", "version of the previous release, default the release before --to")
+	cmd.Flags().String(changelogTo, "", "version of the release or HEAD for the unreleased commits, default the latest release")
+	cmd.Flags().Bool(changelogIncludeMerges, false, "list the merge commits, left out by default")
+	addChangelogFlags(cmd)
+	return cmd
+}
+
+func addChangelogFlags(cmd *cobra.Command) {
+	cmd.Flags().String(changelogFormat, changelog.MarkdownFormat, "changelog format [markdown,json]")
+	cmd.Flags().String(changelogTemplate, "", "go template file of the changelog, overrides --changelog-format, "+
+		"fields: Title, TagPrefix, From, To, Date, Sections with Title, Type and Commits")
+}
+
+func readChangelogFlags(cmd *cobra.Command) (format, template string, err error) {
+	format, err = cmd.Flags().GetString(changelogFormat)
+	if err != nil {
+		return "", "", err
+	}
+	template, err = cmd.Flags().GetString(changelogTemplate)
+	if err != nil {
+		return "", "", err
+	}
+	return format, template, nil
+}
+
+func renderChangelog(cmd *cobra.Command, releaseLog *changelog.Changelog, format, template string) error {
+	if template != "" {
+		return changelog.RenderTemplateFile(cmd.OutOrStdout(), releaseLog, template)
+	}
+	return changelog.Render(cmd.OutOrStdout(), releaseLog, format)
+}
+
+// changelogBounds are the versions and objects of a changelog, empty since and from for the first release.
+type changelogBounds struct {
+	from, to     string
+	since, until plumbing.Hash
+}
+
+// changelogRange resolves to, the latest release by default, and from, the release before to by default.
+func changelogRange(repo *git.Repository, rootFlags *RootFlags, from, to string) (changelogBounds, error) {
+	var bounds changelogBounds
+	var toVersion *semver.Version
+	switch {
+	case to == "":
+		latest, err := latestRef(repo, rootFlags)
+		if err != nil {
+			return bounds, err
+		}
+		bounds.to, bounds.until, toVersion = latest.Version.String(), latest.Ref.Hash(), latest.Version
+	case strings.EqualFold(to, headRevision):
+		head, err := repo.Head()
+		if err != nil {
+			return bounds, err
+		}
+		bounds.to, bounds.until = headRevision, head.Hash()
+	default:
+		ref, err := versionRef(repo, rootFlags.tagPrefix, to)
+		if err != nil {
+			return bounds, err
+		}
+		bounds.to, bounds.until, toVersion = ref.Version.String(), ref.Ref.Hash(), ref.Version
+	}
+	if from != "" {
+		ref, err := versionRef(repo, rootFlags.tagPrefix, from)
+		if err != nil {
+			return bounds, err
+		}
+		bounds.from, bounds.since = ref.Version.String(), ref.Ref.Hash()
+		return bounds, nil
+	}
+	if toVersion == nil {
+		latest, err := latestRef(repo, rootFlags)
+		switch {
+		case errors.Is(err, vergo.ErrNoTagFound):
+			log.Debug("No release yet, the changelog starts at the first commit")
+		case err != nil:
+			return bounds, err
+		default:
+			bounds.from, bounds.since = latest.Version.String(), latest.Ref.Hash()
+		}
+		return bounds, nil
+	}
+	refs, err := vergo.ListRefs(repo, rootFlags.tagPrefix, vergo.DESC, math.MaxInt)
+	if err != nil && !errors.Is(err, vergo.ErrNoTagFound) {
+		return bounds, err
+	}
+	for _, ref := range refs {
+		if ref.Version.LessThan(toVersion) && (toVersion.Prerelease() != "" || ref.Version.Prerelease() == "") {
+			bounds.from, bounds.since = ref.Version.String(), ref.Ref.Hash()
+			break
+		}
+	}
+	return bounds, nil
+}
+
+// versionRef returns the release of the version, given with or without the tag prefix.
+func versionRef(repo *git.Repository, tagPrefix, version string) (vergo.SemverRef, error) {
+	parsed, err := semver.NewVersion(strings.TrimPrefix(version, tagPrefix))
+	if err != nil {
+		return vergo.EmptyRef, fmt.Errorf("%w : %s", ErrInvalidArg, version)
+	}
+	return vergo.VersionRef(repo, tagPrefix, parsed)
+}
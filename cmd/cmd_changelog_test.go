@@ -0,0 +1,160 @@
+package cmd_test
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/sky-uk/vergo/bump"
+	. "github.com/sky-uk/vergo/cmd"
+	vergo "github.com/sky-uk/vergo/git"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func tagHead(t *testing.T, repo *git.Repository, tag string) {
+	t.Helper()
+	head, err := repo.Head()
+	assert.Nil(t, err)
+	_, err = repo.CreateTag(tag, head.Hash(), nil)
+	assert.Nil(t, err)
+}
+
+func changelogSubjects(output string) []string {
+	var subjects []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "- ") {
+			subjects = append(subjects, strings.SplitN(strings.TrimPrefix(line, "- "), " (", 2)[0])
+		}
+	}
+	return subjects
+}
+
+func TestChangelogShouldListCommitsOfRelease(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommitWithMessage(t, repo, "api/a", "feat: first")
+	tagHead(t, repo, "app-1.0.0")
+	DoCommitWithMessage(t, repo, "api/b", "fix: second")
+	tagHead(t, repo, "app-1.0.1-rc.1")
+	DoCommitWithMessage(t, repo, "web/c", "feat: third")
+	tagHead(t, repo, "app-1.1.0")
+	DoCommitWithMessage(t, repo, "api/d", "fix: unreleased")
+
+	testCases := []struct {
+		args     []string
+		subjects []string
+	}{
+		{args: nil, subjects: []string{"third", "second"}},
+		{args: []string{"--paths", "api"}, subjects: []string{"second"}},
+		{args: []string{"--to", "HEAD"}, subjects: []string{"unreleased"}},
+		{args: []string{"--to", "1.0.1-rc.1"}, subjects: []string{"second"}},
+		{args: []string{"--to", "app-1.0.0"}, subjects: []string{"first"}},
+		{args: []string{"--from", "1.0.1-rc.1", "--to", "1.1.0"}, subjects: []string{"third"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(strings.Join(testCase.args, " "), func(t *testing.T) {
+			cmd, buffer := makeChangelog(t)
+			cmd.SetArgs(append([]string{"changelog", "-t", "app", "--repository-location", tempDir}, testCase.args...))
+			assert.Nil(t, cmd.Execute())
+			assert.Equal(t, testCase.subjects, changelogSubjects(readBuffer(t, buffer)))
+		})
+	}
+}
+
+func TestChangelogShouldRenderFormats(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommitWithMessage(t, repo, "a", "feat: first")
+	tagHead(t, repo, "v1.0.0")
+	{
+		cmd, buffer := makeChangelog(t)
+		cmd.SetArgs([]string{"changelog", "--changelog-format", "json", "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+		assert.Contains(t, readBuffer(t, buffer), `"subject": "first"`)
+	}
+	{
+		template := filepath.Join(t.TempDir(), "changelog.tmpl")
+		assert.Nil(t, os.WriteFile(template, []byte("{{ .Title }} from {{ .From }}"), 0600))
+		cmd, buffer := makeChangelog(t)
+		cmd.SetArgs([]string{"changelog", "--changelog-template", template, "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "v1.0.0 from ", readBuffer(t, buffer))
+	}
+	{
+		cmd, _ := makeChangelog(t)
+		cmd.SetArgs([]string{"changelog", "--to", "2.0.0", "--repository-location", tempDir})
+		assert.ErrorIs(t, cmd.Execute(), vergo.ErrNoTagFound)
+	}
+	{
+		cmd, _ := makeChangelog(t)
+		cmd.SetArgs([]string{"changelog", "--to", "not-a-version", "--repository-location", tempDir})
+		assert.ErrorIs(t, cmd.Execute(), ErrInvalidArg)
+	}
+	DoCommitWithMessage(t, repo, "b", "fix: second")
+	tagHead(t, repo, "v1.1")
+	{
+		cmd, buffer := makeChangelog(t)
+		cmd.SetArgs([]string{"changelog", "--to", "1.1.0", "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, []string{"second"}, changelogSubjects(readBuffer(t, buffer)))
+	}
+}
+
+func TestBumpShouldUpdateChangelogFile(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	file := filepath.Join(tempDir, "CHANGELOG.md")
+	assert.Nil(t, os.WriteFile(file, []byte("# Changelog\n\n## [Unreleased]\n\n## [0.1.0] - 2026-10-01\nfirst\n"), 0600))
+	DoCommitWithMessage(t, repo, "a", "feat: first")
+	tagHead(t, repo, "v0.1.0")
+	DoCommitWithMessage(t, repo, "b", "feat: orders")
+	before, err := repo.Head()
+	assert.Nil(t, err)
+	{
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", "minor", "--update-changelog", "--dry-run", "--repository-location", tempDir})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "0.2.0", readBuffer(t, buffer))
+		head, err := repo.Head()
+		assert.Nil(t, err)
+		assert.Equal(t, before.Hash(), head.Hash())
+	}
+	cmd, buffer := makeBumpFunc(t, bump.Bump)
+	cmd.SetArgs([]string{"bump", "minor", "--update-changelog", "--repository-location", tempDir})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "0.2.0", readBuffer(t, buffer))
+
+	content, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Regexp(t, `(?s)^# Changelog\n\n## \[Unreleased\]\n\n## \[0\.2\.0\] - \d{4}-\d\d-\d\d\n\n### Features\n\n- orders \([0-9a-f]{7}\)\n\n## \[0\.1\.0\]`,
+		string(content))
+	head, err := repo.Head()
+	assert.Nil(t, err)
+	commit, err := repo.CommitObject(head.Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, "chore(release): v0.2.0", commit.Message)
+	assert.Equal(t, before.Hash(), commit.ParentHashes[0])
+	latest, err := vergo.LatestRef(repo, "v")
+	assert.Nil(t, err)
+	tagged, err := vergo.TagCommit(repo, latest.Ref)
+	assert.Nil(t, err)
+	assert.Equal(t, head.Hash(), tagged)
+}
+
+func TestBumpShouldNotCommitOtherChangesWithTheChangelog(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommitWithMessage(t, repo, "a", "feat: first")
+	cmd, _ := makeBumpFunc(t, bump.Bump)
+	cmd.SetArgs([]string{"bump", "minor", "--update-changelog", "--push-tag", "--repository-location", tempDir})
+	assert.ErrorIs(t, cmd.Execute(), ErrInvalidArg)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, "staged"), []byte("staged"), 0600))
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+	_, err = worktree.Add("staged")
+	assert.Nil(t, err)
+	cmd, _ = makeBumpFunc(t, bump.Bump)
+	cmd.SetArgs([]string{"bump", "minor", "--update-changelog", "--repository-location", tempDir})
+	assert.ErrorIs(t, cmd.Execute(), vergo.ErrStagedChanges)
+	_, err = vergo.LatestRef(repo, "v")
+	assert.ErrorIs(t, err, vergo.ErrNoTagFound)
+}
@@ -1,8 +1,8 @@
 package cmd
 
 import (
-	"errors"
 	"github.com/go-git/go-git/v5"
+	"github.com/sky-uk/vergo/client"
 	"github.com/sky-uk/vergo/release"
 	"github.com/spf13/cobra"
 )
@@ -17,6 +17,7 @@ func CheckCmd(
 	}
 	cmd.AddCommand(checkReleaseCmd(skipHintPresent, validateHEAD))
 	cmd.AddCommand(checkIncrementHintCmd(skipHintPresent, incrementHint))
+	cmd.AddCommand(checkChangedCmd())
 	return cmd
 }
 
@@ -25,26 +26,7 @@ func checkReleaseCmd(skipHintPresent release.SkipHintPresentFunc, validateHEAD r
 		Use:   "release",
 		Short: "performs release validations",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			rootFlags, err := readRootFlags(cmd)
-			if err != nil {
-				return err
-			}
-			repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
-			if err != nil {
-				return err
-			}
-
-			var errs errs
-			if err := skipHintPresent(repo, rootFlags.tagPrefixRaw); err != nil {
-				errs = append(errs, err)
-			}
-			if err := validateHEAD(repo, rootFlags.remote, rootFlags.versionedBranches); err != nil {
-				errs = append(errs, err)
-			}
-			if len(errs) > 0 {
-				return errs
-			}
-			return nil
+			return check(cmd, client.CheckRelease, client.Backend{SkipHintPresent: skipHintPresent, ValidateHEAD: validateHEAD})
 		},
 	}
 	return cmd
@@ -55,25 +37,47 @@ func checkIncrementHintCmd(skipHintPresent release.SkipHintPresentFunc, incremen
 		Use:   "increment-hint",
 		Short: "checks if commit message includes certain keywords",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			rootFlags, err := readRootFlags(cmd)
-			if err != nil {
-				return err
-			}
-			repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
-			if err != nil {
-				return err
-			}
+			return check(cmd, client.CheckIncrementHint, client.Backend{SkipHintPresent: skipHintPresent, IncrementHint: incrementHint})
+		},
+	}
+	return cmd
+}
 
-			err = skipHintPresent(repo, rootFlags.tagPrefixRaw)
-			if errors.Is(err, release.ErrSkipRelease) {
-				return nil
-			}
-			if err != nil {
-				return err
-			}
-			_, err = incrementHint(repo, rootFlags.tagPrefixRaw)
-			return err
+func checkChangedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "changed",
+		Short: "checks if files under --paths changed since the latest release",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return check(cmd, client.CheckChanged, client.Backend{})
 		},
 	}
 	return cmd
 }
+
+func check(cmd *cobra.Command, check client.Check, backend client.Backend) error {
+	rootFlags, err := readRootFlags(cmd)
+	if err != nil {
+		return err
+	}
+	repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return err
+	}
+	result, err := newProject(repo, rootFlags, backend).Check(cmd.Context(), check)
+	// a skipped increment-hint check passes with the skip hint as reason
+	record := checkRecord{Check: string(check), Reason: result.Skipped, Increment: result.Increment, Changed: result.Changed}
+	return writeCheck(cmd, rootFlags, record, err)
+}
+
+// writeCheck writes the structured output of the check and returns its error.
+func writeCheck(cmd *cobra.Command, rootFlags *RootFlags, record checkRecord, err error) error {
+	if err != nil {
+		record.Passed, record.Reason = false, err.Error()
+	} else {
+		record.Passed = true
+	}
+	if outputErr := writeOutput(cmd, rootFlags.output, record, func() error { return nil }); outputErr != nil {
+		return outputErr
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package cmd_test
 
 import (
 	"fmt"
+	"github.com/sky-uk/vergo/bump"
 	. "github.com/sky-uk/vergo/internal-test"
 	"github.com/sky-uk/vergo/release"
 	"github.com/stretchr/testify/assert"
@@ -75,3 +76,44 @@ func TestCheckReleaseInvalidHeadless(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "Error: commit blah is not on a versioned branch: blah\n", readBuffer(t, buffer))
 }
+
+func TestCheckChanged(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "init")
+	args := []string{"--repository-location", tempDir, "-t", "api", "--paths", "services/api", "--ignore-paths", "**/*.md"}
+	{
+		cmd, _ := makeCheck(t)
+		cmd.SetArgs(append([]string{"check", "changed"}, args...))
+		assert.Nil(t, cmd.Execute(), "no release yet")
+	}
+	{
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs(append([]string{"bump", "minor", "--only-if-changed"}, args...))
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "0.1.0", readBuffer(t, buffer))
+	}
+	DoCommit(t, repo, "services/api/README.md")
+	{
+		cmd, _ := makeCheck(t)
+		cmd.SetArgs(append([]string{"check", "changed"}, args...))
+		assert.ErrorIs(t, cmd.Execute(), release.ErrNoChanges)
+	}
+	{
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs(append([]string{"bump", "minor", "--only-if-changed"}, args...))
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "", readBuffer(t, buffer))
+	}
+	DoCommit(t, repo, "services/api/main.go")
+	{
+		cmd, _ := makeCheck(t)
+		cmd.SetArgs(append([]string{"check", "changed"}, args...))
+		assert.Nil(t, cmd.Execute())
+	}
+	{
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs(append([]string{"bump", "minor", "--only-if-changed"}, args...))
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "0.2.0", readBuffer(t, buffer))
+	}
+}
@@ -1,21 +1,64 @@
 package cmd
 
 const repositoryLocation = "repository-location"
+const configFile = "config"
+const project = "project"
 const versionedBranchNames = "versioned-branch-names"
+const versionLinePatterns = "version-line-patterns"
+const paths = "paths"
+const ignorePaths = "ignore-paths"
+const versionGroup = "version-group"
 const dryRun = "dry-run"
 const nearestRelease = "nearest-release"
 const tagPrefix = "tag-prefix"
 const logLevel = "log-level"
+const logFile = "log-file"
 const remoteName = "remote-name"
 const strictHostChecking = "disable-strict-host-check"
 
 const pushTagParam = "push-tag"
+const allProjects = "all"
+const planOnly = "plan"
+const propagationIncrement = "propagation-increment"
+const conventionalCommits = "conventional-commits"
+const scopeAsPrefix = "scope-as-prefix"
+const preReleaseIdentifier = "pre-release-identifier"
+const preReleaseChannels = "pre-release-channels"
+const allowLower = "allow-lower"
+const onlyIfChanged = "only-if-changed"
 const mergeCommits = "merge-commits"
 
+const changelogFrom = "from"
+const changelogTo = "to"
+const changelogIncludeMerges = "include-merges"
+const changelogFormat = "changelog-format"
+const changelogTemplate = "changelog-template"
+const updateChangelog = "update-changelog"
+const changelogFile = "changelog-file"
+const lightweightTag = "lightweight-tag"
+const tagMessage = "message"
+const tagMessageFile = "message-file"
+const signingKey = "signing-key"
+const ciOutputs = "ci-outputs"
+const ciDotenvFile = "ci-dotenv-file"
+const signingFormat = "signing-format"
+const signingPassphraseEnvVarKey = "signing-passphrase-env-var-key"
+
 const withPrefix = "with-prefix"
+const output = "output"
+const versionFormat = "format"
 const withMetadata = "with-metadata"
+const snapshotStrategy = "snapshot-strategy"
+const snapshotIncrement = "snapshot-increment"
+const snapshotTemplate = "snapshot-template"
 
 const sortDirection = "sort-direction"
 const maxListSize = "max-list-size"
 
 const tokenEnvVarKey = "token-env-var-key"
+
+const initialVersion = "initial-version"
+const initialIncrement = "initial-increment"
+
+const keyring = "keyring"
+const allowedSigners = "allowed-signers"
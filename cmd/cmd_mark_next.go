@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/sky-uk/vergo/bump"
+	"github.com/sky-uk/vergo/client"
+	vergo "github.com/sky-uk/vergo/git"
+	"github.com/spf13/cobra"
+)
+
+// MarkNextCmd returns the mark-next command.
+func MarkNextCmd(markNext bump.MarkNextFunc, pushTag vergo.PushTagFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mark-next <version>",
+		Short: "marks the next version, current-version and bump use it once it is greater than the latest release",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := semver.NewVersion(args[0])
+			if err != nil {
+				return fmt.Errorf("%w : %s", ErrInvalidArg, args[0])
+			}
+			rootFlags, err := readRootFlags(cmd)
+			if err != nil {
+				return err
+			}
+			pushTagParam, err := cmd.Flags().GetBool(pushTagParam)
+			if err != nil {
+				return err
+			}
+			allowLower, err := cmd.Flags().GetBool(allowLower)
+			if err != nil {
+				return err
+			}
+			repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
+			if err != nil {
+				return err
+			}
+			project := newProject(repo, rootFlags, client.Backend{MarkNext: markNext, PushTag: pushTag})
+			version, err = project.Mark(cmd.Context(), version, client.MarkOptions{AllowLower: allowLower})
+			if err != nil {
+				return err
+			}
+			if pushTagParam {
+				if err := project.PushMark(cmd.Context(), version); err != nil {
+					return err
+				}
+			} else {
+				log.Trace("Push not enabled")
+			}
+			tmpl, err := versionTemplate(rootFlags)
+			if err != nil {
+				return err
+			}
+			record := versionRecord{Version: version.String(), Prefix: rootFlags.tagPrefix}
+			if describeVersions(rootFlags) {
+				if record, err = headVersionRecord(repo, rootFlags.tagPrefix, version); err != nil {
+					return err
+				}
+			}
+			return printVersion(cmd, repo, rootFlags, tmpl, version, record)
+		},
+	}
+	cmd.Flags().BoolP(pushTagParam, "u", false, "push the marker tag")
+	cmd.Flags().Bool(allowLower, false, "allow a version which is not greater than the latest release")
+	return cmd
+}
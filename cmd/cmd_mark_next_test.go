@@ -0,0 +1,30 @@
+package cmd_test
+
+import (
+	"github.com/sky-uk/vergo/bump"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMarkNext(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "init")
+	{
+		cmd, buffer := makeMarkNext(t)
+		cmd.SetArgs([]string{"mark-next", "1.0.0", "--repository-location", tempDir, "-t", "app", "-p", "--push-tag"})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "app-1.0.0", readBuffer(t, buffer))
+	}
+	{
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", "patch", "--repository-location", tempDir, "-t", "app"})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "1.0.0", readBuffer(t, buffer))
+	}
+	{
+		cmd, _ := makeMarkNext(t)
+		cmd.SetArgs([]string{"mark-next", "0.9.0", "--repository-location", tempDir, "-t", "app"})
+		assert.ErrorIs(t, cmd.Execute(), bump.ErrVersionNotGreater)
+	}
+}
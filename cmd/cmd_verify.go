@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	vergo "github.com/sky-uk/vergo/git"
+	"github.com/spf13/cobra"
+	"math"
+	"os"
+)
+
+func VerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [latest-release|current-version|<version>]",
+		Short: "verifies the signature of a release tag against trusted keys",
+		Long: "verifies that the release tag, by default the latest release, is signed by a key of --keyring " +
+			"or --allowed-signers and prints the signer, unsigned and untrusted tags fail",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootFlags, err := readRootFlags(cmd)
+			if err != nil {
+				return err
+			}
+			trusted, err := readTrustedKeys(cmd)
+			if err != nil {
+				return err
+			}
+			version := "latest-release"
+			if len(args) > 0 {
+				version = args[0]
+			}
+			repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
+			if err != nil {
+				return err
+			}
+			ref, err := verifiedRef(repo, rootFlags, version)
+			if err != nil {
+				return err
+			}
+			signature, err := vergo.VerifyTag(repo, ref.Ref.Name().Short(), trusted)
+			if err != nil {
+				return err
+			}
+			cmd.Printf("%s signed by %s (%s %s)\n", signature.Tag, signature.Signer, signature.Format, signature.Fingerprint)
+			return nil
+		},
+	}
+	cmd.Flags().StringSlice(keyring, nil, "armored or binary OpenPGP public key files trusted to sign releases")
+	cmd.Flags().StringSlice(allowedSigners, nil, "ssh allowed signers files trusted to sign releases, "+
+		"the format of git gpg.ssh.allowedSignersFile")
+	return cmd
+}
+
+func readTrustedKeys(cmd *cobra.Command) (vergo.TrustedKeys, error) {
+	var trusted vergo.TrustedKeys
+	keyrings, err := cmd.Flags().GetStringSlice(keyring)
+	if err != nil {
+		return trusted, err
+	}
+	signersFiles, err := cmd.Flags().GetStringSlice(allowedSigners)
+	if err != nil {
+		return trusted, err
+	}
+	if len(keyrings) == 0 && len(signersFiles) == 0 {
+		return trusted, fmt.Errorf("%w : --%s or --%s is required", ErrInvalidArg, keyring, allowedSigners)
+	}
+	for _, file := range keyrings {
+		content, err := os.Open(file)
+		if err != nil {
+			return trusted, err
+		}
+		entities, err := vergo.ReadOpenPGPKeyring(content)
+		_ = content.Close()
+		if err != nil {
+			return trusted, fmt.Errorf("%w : %s", err, file)
+		}
+		trusted.OpenPGP = append(trusted.OpenPGP, entities...)
+	}
+	for _, file := range signersFiles {
+		content, err := os.Open(file)
+		if err != nil {
+			return trusted, err
+		}
+		signers, err := vergo.ReadAllowedSigners(content)
+		_ = content.Close()
+		if err != nil {
+			return trusted, fmt.Errorf("%w : %s", err, file)
+		}
+		trusted.AllowedSigners = append(trusted.AllowedSigners, signers...)
+	}
+	return trusted, nil
+}
+
+// verifiedRef returns the latest release, the release tagged at HEAD for current-version or the release of the version.
+func verifiedRef(repo *git.Repository, rootFlags *RootFlags, version string) (vergo.SemverRef, error) {
+	switch version {
+	case "lr", "latest-release":
+		return latestRef(repo, rootFlags)
+	case "cv", "current-version":
+		head, err := repo.Head()
+		if err != nil {
+			return vergo.EmptyRef, err
+		}
+		refs, err := vergo.ListRefs(repo, rootFlags.tagPrefix, vergo.DESC, math.MaxInt)
+		if err != nil {
+			return vergo.EmptyRef, err
+		}
+		for _, ref := range refs {
+			commit, err := vergo.TagCommit(repo, ref.Ref)
+			if err != nil {
+				return vergo.EmptyRef, err
+			}
+			if commit == head.Hash() {
+				return ref, nil
+			}
+		}
+		return vergo.EmptyRef, fmt.Errorf("%w : HEAD is not a release of %s", vergo.ErrNoTagFound, rootFlags.tagPrefix)
+	default:
+		return versionRef(repo, rootFlags.tagPrefix, version)
+	}
+}
@@ -0,0 +1,76 @@
+package cmd_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/sky-uk/vergo/cmd"
+	vergo "github.com/sky-uk/vergo/git"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func sshTagSigner(t *testing.T) (*vergo.SSHSigner, string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	assert.Nil(t, err)
+	return &vergo.SSHSigner{PublicKey: signer.PublicKey(), Signer: signer}, string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+}
+
+func TestVerifyShouldCheckTagSignatures(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	trusted, trustedKey := sshTagSigner(t)
+	untrusted, _ := sshTagSigner(t)
+	tagger := &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()}
+	sign := func(version string, signer vergo.TagSigner) {
+		DoCommit(t, repo, version)
+		assert.Nil(t, vergo.CreateTagWithOptions(repo, version, "app-",
+			vergo.TagOptions{Message: "Release app-" + version, Tagger: tagger, Signer: signer}, false))
+	}
+	sign("1.0.0", trusted)
+	sign("1.1.0", untrusted)
+	DoCommit(t, repo, "1.2.0")
+	tagHead(t, repo, "app-1.2.0")
+	sign("1.3.0", trusted)
+	sign("1.4", trusted)
+	allowed := filepath.Join(t.TempDir(), "allowed_signers")
+	assert.Nil(t, os.WriteFile(allowed, []byte("release@example.com namespaces=\"git\" "+trustedKey), 0600))
+
+	testCases := []struct {
+		version, output string
+		err             error
+	}{
+		{version: "latest-release", output: "app-1.4 signed by release@example.com (ssh SHA256:"},
+		{version: "current-version", output: "app-1.4 signed by release@example.com (ssh SHA256:"},
+		{version: "1.3.0", output: "app-1.3.0 signed by release@example.com (ssh SHA256:"},
+		{version: "1.0.0", output: "app-1.0.0 signed by release@example.com (ssh SHA256:"},
+		{version: "app-1.1.0", err: vergo.ErrUntrustedSignature},
+		{version: "1.2.0", err: vergo.ErrUnsignedTag},
+		{version: "2.0.0", err: vergo.ErrNoTagFound},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.version, func(t *testing.T) {
+			cmd, buffer := makeVerify(t)
+			cmd.SetArgs([]string{"verify", testCase.version, "-t", "app", "--repository-location", tempDir,
+				"--allowed-signers", allowed})
+			err := cmd.Execute()
+			if testCase.err != nil {
+				assert.ErrorIs(t, err, testCase.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Contains(t, readBuffer(t, buffer), testCase.output)
+		})
+	}
+
+	cmd, _ := makeVerify(t)
+	cmd.SetArgs([]string{"verify", "-t", "app", "--repository-location", tempDir})
+	assert.ErrorIs(t, cmd.Execute(), ErrInvalidArg)
+}
@@ -29,6 +29,10 @@ func mockPushTagSuccess(_ *git.Repository, _, _, _ string, _ bool, _ bool, _ str
 	return nil
 }
 
+func mockPushTagsSuccess(_ *git.Repository, _ []string, _ string, _ bool, _ bool, _ string) error {
+	return nil
+}
+
 func mockPushTagFailure(_ *git.Repository, _, _, _ string, _ bool, _ bool, _ string) error {
 	return errors.New("push tag failed")
 }
@@ -51,30 +55,40 @@ func checkReleaseDependencies(t *testing.T, skipHintPresentErr error, validateHE
 func makeBump(t *testing.T) (*cobra.Command, *bytes.Buffer) {
 	t.Helper()
 	cmd := RootCmd()
-	cmd.AddCommand(BumpCmd(bumpSuccess(t), mockPushTagSuccess))
+	cmd.AddCommand(BumpCmd(bumpSuccess(t), bump.Set, mockPushTagSuccess, mockPushTagsSuccess))
 	b := bytes.NewBufferString("")
 	cmd.SetOut(b)
-	cmd.SetErr(b)
+	cmd.SetErr(io.Discard)
 	return cmd, b
 }
 
-func makeBumpFunc(t *testing.T, bump bump.Func) (*cobra.Command, *bytes.Buffer) {
+func makeBumpFunc(t *testing.T, bumpFunc bump.Func) (*cobra.Command, *bytes.Buffer) {
 	t.Helper()
 	cmd := RootCmd()
-	cmd.AddCommand(BumpCmd(bump, mockPushTagSuccess))
+	cmd.AddCommand(BumpCmd(bumpFunc, bump.Set, mockPushTagSuccess, mockPushTagsSuccess))
 	b := bytes.NewBufferString("")
 	cmd.SetOut(b)
-	cmd.SetErr(b)
+	cmd.SetErr(io.Discard)
 	return cmd, b
 }
 
 func pushTagFail(t *testing.T) (*cobra.Command, *bytes.Buffer) {
 	t.Helper()
 	cmd := RootCmd()
-	cmd.AddCommand(BumpCmd(bumpSuccess(t), mockPushTagFailure))
+	cmd.AddCommand(BumpCmd(bumpSuccess(t), bump.Set, mockPushTagFailure, mockPushTagsSuccess))
 	b := bytes.NewBufferString("")
 	cmd.SetOut(b)
-	cmd.SetErr(b)
+	cmd.SetErr(io.Discard)
+	return cmd, b
+}
+
+func makeMarkNext(t *testing.T) (*cobra.Command, *bytes.Buffer) {
+	t.Helper()
+	cmd := RootCmd()
+	cmd.AddCommand(MarkNextCmd(bump.MarkNext, mockPushTagSuccess))
+	b := bytes.NewBufferString("")
+	cmd.SetOut(b)
+	cmd.SetErr(io.Discard)
 	return cmd, b
 }
 
@@ -116,7 +130,7 @@ func makeGet(t *testing.T, current vergo.CurrentVersionFunc) (*cobra.Command, *b
 	cmd.AddCommand(GetCmd(latest, previous, current))
 	b := bytes.NewBufferString("")
 	cmd.SetOut(b)
-	cmd.SetErr(b)
+	cmd.SetErr(io.Discard)
 	return cmd, b
 }
 
@@ -132,7 +146,27 @@ func makeList(t *testing.T) (*cobra.Command, *bytes.Buffer) {
 	cmd.AddCommand(ListCmd(emptyListRef))
 	b := bytes.NewBufferString("")
 	cmd.SetOut(b)
-	cmd.SetErr(b)
+	cmd.SetErr(io.Discard)
+	return cmd, b
+}
+
+func makeChangelog(t *testing.T) (*cobra.Command, *bytes.Buffer) {
+	t.Helper()
+	cmd := RootCmd()
+	cmd.AddCommand(ChangelogCmd())
+	b := bytes.NewBufferString("")
+	cmd.SetOut(b)
+	cmd.SetErr(io.Discard)
+	return cmd, b
+}
+
+func makeVerify(t *testing.T) (*cobra.Command, *bytes.Buffer) {
+	t.Helper()
+	cmd := RootCmd()
+	cmd.AddCommand(VerifyCmd())
+	b := bytes.NewBufferString("")
+	cmd.SetOut(b)
+	cmd.SetErr(io.Discard)
 	return cmd, b
 }
 
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/sky-uk/vergo/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"strings"
+)
+
+var ErrNoConfig = errors.New("no config found")
+
+// applyConfig sets the flags not given on the command line from VERGO_<FLAG> variables, then the config file.
+func applyConfig(cmd *cobra.Command) error {
+	if err := applyEnv(cmd); err != nil {
+		return err
+	}
+	repoConfig, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	projectName, err := cmd.Flags().GetString(project)
+	if err != nil {
+		return err
+	}
+	if repoConfig == nil {
+		if projectName != "" {
+			return fmt.Errorf("%w : project %s requires %s", ErrNoConfig, projectName, config.FileName)
+		}
+		return nil
+	}
+	prefix, err := cmd.Flags().GetString(tagPrefix)
+	if err != nil {
+		return err
+	}
+	projectName, err = repoConfig.Project(projectName, prefix)
+	if err != nil {
+		return err
+	}
+	return applyProject(cmd, repoConfig, projectName)
+}
+
+func applyEnv(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+		if value, ok := os.LookupEnv(config.EnvName(flag.Name)); ok {
+			log.Debugf("Flag %s set from %s", flag.Name, config.EnvName(flag.Name))
+			err = cmd.Flags().Set(flag.Name, value)
+		}
+	})
+	return err
+}
+
+// applyProject resets the flags not given by the user and applies the config of the project.
+func applyProject(cmd *cobra.Command, repoConfig *config.Config, projectName string) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err == nil && !flag.Changed {
+			err = setFlag(flag, defaultValues(flag))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for name, values := range repoConfig.Values(projectName) {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		log.Debugf("Flag %s set from %s", name, config.FileName)
+		if err := setFlag(flag, values); err != nil {
+			return fmt.Errorf("%w : %s %s", config.ErrInvalidConfig, name, err)
+		}
+	}
+	return nil
+}
+
+func setFlag(flag *pflag.Flag, values []string) error {
+	if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+		return sliceValue.Replace(values)
+	}
+	if len(values) == 0 {
+		return flag.Value.Set("")
+	}
+	return flag.Value.Set(values[0])
+}
+
+// defaultValues returns the default of the flag, slice defaults are formatted as [a,b]
+func defaultValues(flag *pflag.Flag) []string {
+	if _, ok := flag.Value.(pflag.SliceValue); !ok {
+		return []string{flag.DefValue}
+	}
+	values := strings.TrimSuffix(strings.TrimPrefix(flag.DefValue, "["), "]")
+	if values == "" {
+		return []string{}
+	}
+	return strings.Split(values, ",")
+}
+
+// loadConfig loads --config or the config file at the root of the repository, nil when there is none.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	configFile, err := cmd.Flags().GetString(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if configFile != "" {
+		repoConfig, err := config.Load(configFile)
+		if err == nil && repoConfig == nil {
+			return nil, fmt.Errorf("%w : %s", ErrNoConfig, configFile)
+		}
+		return repoConfig, err
+	}
+	repositoryLocation, err := cmd.Flags().GetString(repositoryLocation)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.PlainOpenWithOptions(repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		log.WithError(err).Debugf("No repository at %s, skipping %s", repositoryLocation, config.FileName)
+		return nil, nil
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		log.WithError(err).Debugf("No worktree at %s, skipping %s", repositoryLocation, config.FileName)
+		return nil, nil
+	}
+	return config.Discover(worktree.Filesystem.Root())
+}
@@ -0,0 +1,56 @@
+package cmd_test
+
+import (
+	"github.com/sky-uk/vergo/bump"
+	. "github.com/sky-uk/vergo/cmd"
+	"github.com/sky-uk/vergo/config"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const repoConfig = `
+initial-version: 0.5.0
+projects:
+  api:
+    tag-prefix: api
+    initial-version: 1.0.0
+`
+
+//nolint:paralleltest
+func TestFlagsShouldFallBackToEnvAndConfig(t *testing.T) {
+	bumpAndRead := func(args ...string) string {
+		repo, tempDir := PersistentRepository(t)
+		DoCommit(t, repo, "init")
+		assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(repoConfig), 0600))
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs(append([]string{"bump", "minor", "--repository-location", tempDir, "-p"}, args...))
+		assert.Nil(t, cmd.Execute())
+		return readBuffer(t, buffer)
+	}
+	assert.Equal(t, "v0.5.0", bumpAndRead(), "top level settings")
+	assert.Equal(t, "api-1.0.0", bumpAndRead("--project", "api"), "project selected by name")
+	assert.Equal(t, "api-1.0.0", bumpAndRead("-t", "api"), "project selected by tag prefix")
+	assert.Equal(t, "api-2.0.0", bumpAndRead("-t", "api", "--initial-version", "2.0.0"), "flag overrides config")
+
+	t.Setenv("VERGO_INITIAL_VERSION", "3.0.0")
+	assert.Equal(t, "api-3.0.0", bumpAndRead("--project", "api"), "env overrides config")
+	assert.Equal(t, "api-2.0.0", bumpAndRead("--project", "api", "--initial-version", "2.0.0"), "flag overrides env")
+}
+
+func TestUnknownProjectShouldFail(t *testing.T) {
+	_, tempDir := PersistentRepository(t)
+	{
+		cmd, _ := makeBump(t)
+		cmd.SetArgs([]string{"bump", "minor", "--repository-location", tempDir, "--project", "api"})
+		assert.ErrorIs(t, cmd.Execute(), ErrNoConfig)
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(tempDir, config.FileName), []byte(repoConfig), 0600))
+	{
+		cmd, _ := makeBump(t)
+		cmd.SetArgs([]string{"bump", "minor", "--repository-location", tempDir, "--project", "web"})
+		assert.ErrorIs(t, cmd.Execute(), config.ErrUnknownProject)
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	vergo "github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/release"
+	"github.com/spf13/cobra"
+	"io"
+	"strings"
+	"text/template"
+)
+
+const (
+	defaultFormat    = "{{.Version}}"
+	withPrefixFormat = "{{.Prefix}}{{.Version}}"
+)
+
+// formatFuncs are the pipeable functions of --format e.g. {{ .Version | replace "+" "-" }}
+var formatFuncs = template.FuncMap{
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+}
+
+// formatData is the data of --format templates.
+type formatData struct {
+	// Version is the version without tag prefix e.g. 1.2.3-rc.1+build.5
+	Version             string
+	Major, Minor, Patch uint64
+	// Prerelease and Metadata are the parts after - and + e.g. rc.1 and build.5
+	Prerelease, Metadata string
+	// Prefix is the tag prefix and Tag the tag of the version, empty when the version is not tagged e.g. a SNAPSHOT
+	Prefix, Tag string
+	// Commit and ShortCommit are the hash of the tagged commit, HEAD when the version is not tagged
+	Commit, ShortCommit string
+	// Branch is the branch of HEAD, empty on a detached HEAD
+	Branch   string
+	distance func() (int, error)
+}
+
+// Distance returns the number of commits from the tagged commit or the latest release to HEAD.
+func (d formatData) Distance() (int, error) {
+	return d.distance()
+}
+
+// versionTemplate parses --format and rejects unknown fields, the version by default.
+func versionTemplate(rootFlags *RootFlags) (*template.Template, error) {
+	text := rootFlags.format
+	if text == "" {
+		text = defaultFormat
+		if rootFlags.withPrefix {
+			text = withPrefixFormat
+		}
+	}
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w : --%s %s", ErrInvalidArg, versionFormat, err)
+	}
+	empty := formatData{distance: func() (int, error) { return 0, nil }}
+	if err := tmpl.Execute(io.Discard, empty); err != nil {
+		return nil, fmt.Errorf("%w : --%s %s", ErrInvalidArg, versionFormat, err)
+	}
+	return tmpl, nil
+}
+
+func newFormatData(repo *git.Repository, rootFlags *RootFlags, version *semver.Version, record versionRecord) formatData {
+	data := formatData{
+		Version:     version.String(),
+		Major:       version.Major(),
+		Minor:       version.Minor(),
+		Patch:       version.Patch(),
+		Prerelease:  version.Prerelease(),
+		Metadata:    version.Metadata(),
+		Prefix:      rootFlags.tagPrefix,
+		Tag:         record.Tag,
+		Commit:      record.Commit,
+		ShortCommit: record.Commit,
+	}
+	if len(data.ShortCommit) > 7 {
+		data.ShortCommit = data.ShortCommit[:7]
+	}
+	head, err := repo.Head()
+	if err == nil && head.Name().IsBranch() {
+		data.Branch = head.Name().Short()
+	}
+	data.distance = func() (int, error) {
+		if err != nil {
+			return 0, err
+		}
+		since := plumbing.NewHash(record.Commit)
+		if record.Tag == "" {
+			latest, err := latestRef(repo, rootFlags)
+			switch {
+			case errors.Is(err, vergo.ErrNoTagFound):
+				since = plumbing.ZeroHash
+			case err != nil:
+				return 0, err
+			default:
+				since = latest.Ref.Hash()
+			}
+		}
+		commits, err := release.CommitsBetween(repo, since, head.Hash())
+		return len(commits), err
+	}
+	return data
+}
+
+// printVersion writes the version described by the record with the --format template.
+func printVersion(cmd *cobra.Command, repo *git.Repository, rootFlags *RootFlags, tmpl *template.Template,
+	version *semver.Version, record versionRecord) error {
+	var formatted bytes.Buffer
+	if err := tmpl.Execute(&formatted, newFormatData(repo, rootFlags, version, record)); err != nil {
+		return fmt.Errorf("%w : --%s %s", ErrInvalidArg, versionFormat, err)
+	}
+	cmd.Print(formatted.String())
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd_test
+
+import (
+	"bytes"
+	"github.com/go-git/go-git/v5"
+	"github.com/sky-uk/vergo/bump"
+	. "github.com/sky-uk/vergo/cmd"
+	vergo "github.com/sky-uk/vergo/git"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/sky-uk/vergo/release"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetShouldFormatVersion(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "first")
+	tagHead(t, repo, "app-1.2.3-rc.1+build.5")
+	DoCommit(t, repo, "second")
+	DoCommit(t, repo, "third")
+	head, err := repo.Head()
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		modifier, format, expected string
+	}{
+		{modifier: "latest-release", format: "{{.Major}}.{{.Minor}}", expected: "1.2"},
+		{modifier: "latest-release", format: "{{.Prefix}}{{.Version | replace \"+\" \"-\"}}", expected: "app-1.2.3-rc.1-build.5"},
+		{modifier: "latest-release", format: "{{.Patch}} {{.Prerelease}} {{.Metadata}} {{.Tag}}", expected: "3 rc.1 build.5 app-1.2.3-rc.1+build.5"},
+		{modifier: "latest-release", format: "{{.Branch}}/{{.Distance}}", expected: "master/2"},
+		{modifier: "current-version", format: "{{.Tag}}{{.ShortCommit}}/{{.Distance}}", expected: head.Hash().String()[:7] + "/2"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.format, func(t *testing.T) {
+			cmd := RootCmd()
+			cmd.AddCommand(GetCmd(vergo.LatestRef, vergo.PreviousRef,
+				func(_ *git.Repository, _ string, _ release.PreReleaseFunc, _ vergo.GetOptions) (vergo.SemverRef, error) {
+					return vergo.SemverRef{Version: NewVersionT(t, "1.3.0-SNAPSHOT")}, nil
+				}))
+			buffer := bytes.NewBufferString("")
+			cmd.SetOut(buffer)
+			cmd.SetArgs([]string{"get", testCase.modifier, "--repository-location", tempDir, "-t", "app", "--format", testCase.format})
+			assert.Nil(t, cmd.Execute())
+			assert.Equal(t, testCase.expected, readBuffer(t, buffer))
+		})
+	}
+}
+
+func TestBumpShouldRejectInvalidFormatBeforeTagging(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "first")
+	for _, format := range []string{"{{.Version", "{{.Unknown}}"} {
+		cmd, _ := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", "minor", "--repository-location", tempDir, "-t", "app", "--format", format})
+		assert.ErrorIs(t, cmd.Execute(), ErrInvalidArg)
+	}
+	tags, err := repo.Tags()
+	assert.Nil(t, err)
+	_, err = tags.Next()
+	assert.Error(t, err)
+
+	cmd, buffer := makeBumpFunc(t, bump.Bump)
+	cmd.SetArgs([]string{"bump", "minor", "--repository-location", tempDir, "-t", "app", "--format", "{{.Tag}} {{.Major}}"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "app-0.1.0 0", readBuffer(t, buffer))
+}
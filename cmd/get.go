@@ -1,15 +1,16 @@
 package cmd
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sky-uk/vergo/client"
 	vergo "github.com/sky-uk/vergo/git"
 	"github.com/sky-uk/vergo/release"
 	"github.com/spf13/cobra"
 	"github.com/thoas/go-funk"
+	"strings"
 )
 
 func ExactValidArgs(n int) cobra.PositionalArgs {
@@ -38,7 +39,7 @@ func OnlyValidArgsAndAliases(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-type RefFunc func(repo *git.Repository, prefix string) (vergo.SemverRef, error)
+type RefFunc = client.RefFunc
 
 func GetCmd(latest, previous RefFunc, current vergo.CurrentVersionFunc) *cobra.Command {
 	cmd := &cobra.Command{
@@ -57,40 +58,68 @@ func GetCmd(latest, previous RefFunc, current vergo.CurrentVersionFunc) *cobra.C
 			if err != nil {
 				return err
 			}
-			ref, err := get(latest, previous, current, rootFlags, modifier, withMetadata)
+			strategy, err := cmd.Flags().GetString(snapshotStrategy)
 			if err != nil {
 				return err
 			}
-			if rootFlags.withPrefix {
-				cmd.Print(rootFlags.tagPrefix, ref.Version.String())
-			} else {
-				cmd.Print(ref.Version.String())
+			increment, err := cmd.Flags().GetString(snapshotIncrement)
+			if err != nil {
+				return err
+			}
+			template, err := cmd.Flags().GetString(snapshotTemplate)
+			if err != nil {
+				return err
+			}
+			repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
+			if err != nil {
+				return err
+			}
+			project := newProject(repo, rootFlags, client.Backend{Latest: latest, Previous: previous, Current: current})
+			version, err := get(cmd.Context(), project, modifier, client.CurrentOptions{
+				WithMetadata: withMetadata,
+				Increment:    increment,
+				Strategy:     strategy,
+				Template:     template,
+			})
+			if err != nil {
+				return err
+			}
+			record := versionRecord{Version: version.String(), Prefix: rootFlags.tagPrefix}
+			if describeVersions(rootFlags) {
+				describe := newVersionRecord
+				if modifier == "cv" || modifier == "current-version" {
+					describe = headVersionRecord
+				}
+				if record, err = describe(repo, rootFlags.tagPrefix, version); err != nil {
+					return err
+				}
 			}
-			return nil
+			return writeOutput(cmd, rootFlags.output, record, func() error {
+				tmpl, err := versionTemplate(rootFlags)
+				if err != nil {
+					return err
+				}
+				return printVersion(cmd, repo, rootFlags, tmpl, version, record)
+			})
 		},
 	}
 	cmd.Flags().BoolP(withMetadata, "m", false, "returns current version with commit hash as metadata")
+	cmd.Flags().String(snapshotStrategy, release.SnapshotStrategy, "current version pre-release strategy ["+strings.Join(release.SnapshotStrategies, ",")+"]")
+	cmd.Flags().String(snapshotIncrement, "minor", "increment applied to the latest release for the current version [patch,minor,major]")
+	cmd.Flags().String(snapshotTemplate, "", "go template of the current version e.g. {{.Next}}-{{.Branch}}.{{.Distance}}+{{.ShortSHA}}, "+
+		"fields: Version, Next, Branch, Distance, SHA, ShortSHA, Timestamp")
 	return cmd
 }
 
-func get(latest, previous RefFunc, current vergo.CurrentVersionFunc, rootFlags *RootFlags, modifier string, withMetadata bool) (vergo.SemverRef, error) {
-	repo, err := git.PlainOpenWithOptions(rootFlags.repositoryLocation, &git.PlainOpenOptions{DetectDotGit: true})
-	if err != nil {
-		return vergo.EmptyRef, err
-	}
-
+func get(ctx context.Context, project *client.Project, modifier string, currentOptions client.CurrentOptions) (*semver.Version, error) {
 	switch modifier {
 	case "lr", "latest-release":
-		return latest(repo, rootFlags.tagPrefix)
+		return project.Latest(ctx)
 	case "pr", "previous-release":
-		return previous(repo, rootFlags.tagPrefix)
+		return project.Previous(ctx)
 	case "cv", "current-version":
-		ref, err := current(repo, rootFlags.tagPrefix, release.PreRelease(repo, release.PreReleaseOptions{WithMetadata: withMetadata}), vergo.GetOptions{NearestRelease: rootFlags.nearestRelease})
-		if errors.Is(err, plumbing.ErrReferenceNotFound) || errors.Is(err, vergo.ErrNoTagFound) {
-			return vergo.SemverRef{Version: semver.MustParse("0.0.0-SNAPSHOT")}, nil
-		}
-		return ref, err
+		return project.Current(ctx, currentOptions)
 	default:
-		return vergo.EmptyRef, fmt.Errorf("%w : %s", ErrInvalidArg, modifier)
+		return nil, fmt.Errorf("%w : %s", ErrInvalidArg, modifier)
 	}
 }
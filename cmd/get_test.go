@@ -97,3 +97,14 @@ func TestGetDetectDotGit(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "0.1.0", readBuffer(t, buffer))
 }
+
+func TestGetCurrentVersionShouldReturnInitialVersionWhenNoTagFound(t *testing.T) {
+	_, tempDir := PersistentRepository(t)
+	cmd, buffer := makeGet(t, func(_ *git.Repository, _ string, _ release.PreReleaseFunc, _ vergo.GetOptions) (vergo.SemverRef, error) {
+		return vergo.EmptyRef, vergo.ErrNoTagFound
+	})
+	cmd.SetArgs([]string{"get", "cv", "--repository-location", tempDir, "-t", "some-prefix", "--initial-version", "1.0.0"})
+	err := cmd.Execute()
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0.0-SNAPSHOT", readBuffer(t, buffer))
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"errors"
+	"github.com/sky-uk/vergo/client"
 	"strings"
 )
 
@@ -10,16 +11,25 @@ var (
 )
 
 func sanitiseTagPrefix(tagPrefix string) string {
-	switch tagPrefix := strings.ToLower(strings.TrimSpace(tagPrefix)); {
-	case tagPrefix == "":
-		return "v"
+	return client.TagPrefix(tagPrefix)
+}
+
+func sanitiseTagPrefixes(tagPrefixes []string) []string {
+	sanitised := make([]string, 0, len(tagPrefixes))
+	for _, tagPrefix := range tagPrefixes {
+		sanitised = append(sanitised, sanitiseTagPrefix(tagPrefix))
+	}
+	return sanitised
+}
+
+// rawTagPrefix reverts sanitiseTagPrefix e.g. app- -> app and v -> empty
+func rawTagPrefix(tagPrefix string) string {
+	switch {
 	case tagPrefix == "v":
-		return "v"
+		return ""
 	case strings.HasSuffix(tagPrefix, "-"):
-		return tagPrefix
-	case strings.HasSuffix(tagPrefix, "/v"):
-		return tagPrefix
+		return strings.TrimSuffix(tagPrefix, "-")
 	default:
-		return tagPrefix + "-"
+		return tagPrefix
 	}
 }
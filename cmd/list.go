@@ -42,16 +42,29 @@ func ListCmd(listRefs ListRefs) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			records := make([]versionRecord, 0, len(refs))
 			for _, ref := range refs {
-				if rootFlags.withPrefix {
-					cmd.Print(rootFlags.tagPrefix, ref.Version.String())
-					cmd.Println()
-				} else {
-					cmd.Print(ref.Version.String())
-					cmd.Println()
+				record := versionRecord{Version: ref.Version.String(), Prefix: rootFlags.tagPrefix}
+				if describeVersions(rootFlags) {
+					if record, err = newVersionRecord(repo, rootFlags.tagPrefix, ref.Version); err != nil {
+						return err
+					}
 				}
+				records = append(records, record)
 			}
-			return nil
+			return writeOutput(cmd, rootFlags.output, records, func() error {
+				tmpl, err := versionTemplate(rootFlags)
+				if err != nil {
+					return err
+				}
+				for i, ref := range refs {
+					if err := printVersion(cmd, repo, rootFlags, tmpl, ref.Version, records[i]); err != nil {
+						return err
+					}
+					cmd.Println()
+				}
+				return nil
+			})
 		},
 	}
 	cmd.Flags().String(sortDirection, "desc", "sort direction [asc,desc]")
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	vergo "github.com/sky-uk/vergo/git"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+	yamlOutput = "yaml"
+	envOutput  = "env"
+	// envPrefix prefixes the variables of the env output e.g. VERGO_VERSION, VERGO_0_VERSION for lists
+	envPrefix = "VERGO_"
+)
+
+var outputFormats = []string{textOutput, jsonOutput, yamlOutput, envOutput}
+
+// versionRecord is the structured output of a version, Tag is empty for an untagged version.
+type versionRecord struct {
+	Project   string `json:"project,omitempty" yaml:"project,omitempty"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	Prefix    string `json:"prefix" yaml:"prefix"`
+	Tag       string `json:"tag" yaml:"tag"`
+	Commit    string `json:"commit" yaml:"commit"`
+	Annotated bool   `json:"annotated" yaml:"annotated"`
+	Tagger    string `json:"tagger,omitempty" yaml:"tagger,omitempty"`
+	Date      string `json:"date,omitempty" yaml:"date,omitempty"`
+	Created   *bool  `json:"created,omitempty" yaml:"created,omitempty"`
+	Previous  string `json:"previous,omitempty" yaml:"previous,omitempty"`
+	Skipped   string `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Cause     string `json:"cause,omitempty" yaml:"cause,omitempty"`
+}
+
+// checkRecord is the structured output of check, Reason is the error of a failed check.
+type checkRecord struct {
+	Check     string   `json:"check" yaml:"check"`
+	Passed    bool     `json:"passed" yaml:"passed"`
+	Reason    string   `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Increment string   `json:"increment,omitempty" yaml:"increment,omitempty"`
+	Changed   []string `json:"changed,omitempty" yaml:"changed,omitempty"`
+}
+
+func parseOutputFormat(format string) (string, error) {
+	for _, known := range outputFormats {
+		if strings.EqualFold(format, known) {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("%w : --%s %s", ErrInvalidArg, output, format)
+}
+
+// newVersionRecord describes the tag of the version, vergo.ErrNoTagFound when it is not tagged.
+func newVersionRecord(repo *git.Repository, prefix string, version *semver.Version) (versionRecord, error) {
+	record := versionRecord{Version: version.String(), Prefix: prefix}
+	ref, err := vergo.VersionRef(repo, prefix, version)
+	if err != nil {
+		return record, err
+	}
+	info, err := vergo.DescribeTag(repo, ref.Ref.Name().Short())
+	if err != nil {
+		return record, err
+	}
+	record.Tag, record.Commit, record.Annotated, record.Date =
+		info.Name, info.Commit.String(), info.Annotated, info.Date.Format(time.RFC3339)
+	if info.Tagger != nil {
+		record.Tagger = fmt.Sprintf("%s <%s>", info.Tagger.Name, info.Tagger.Email)
+	}
+	return record, nil
+}
+
+// describeVersions returns true when the output or the --format template describes the tags of the versions.
+func describeVersions(rootFlags *RootFlags) bool {
+	return rootFlags.output != textOutput || rootFlags.format != ""
+}
+
+// headVersionRecord describes the version of HEAD, also when it is not tagged.
+func headVersionRecord(repo *git.Repository, prefix string, version *semver.Version) (versionRecord, error) {
+	record, err := newVersionRecord(repo, prefix, version)
+	if !errors.Is(err, vergo.ErrNoTagFound) {
+		return record, err
+	}
+	head, err := repo.Head()
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		return record, nil
+	case err != nil:
+		return record, err
+	}
+	record.Commit = head.Hash().String()
+	return record, nil
+}
+
+// writeOutput writes a record or a slice of records in the structured format, text writes the text format.
+func writeOutput(cmd *cobra.Command, format string, value interface{}, text func() error) error {
+	switch format {
+	case jsonOutput:
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case yamlOutput:
+		encoder := yaml.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case envOutput:
+		var lines []string
+		records := reflect.ValueOf(value)
+		if records.Kind() == reflect.Slice {
+			for i := 0; i < records.Len(); i++ {
+				lines = append(lines, envLines(envPrefix+strconv.Itoa(i)+"_", records.Index(i))...)
+			}
+		} else {
+			lines = envLines(envPrefix, records)
+		}
+		for _, line := range lines {
+			cmd.Println(line)
+		}
+		return nil
+	default:
+		return text()
+	}
+}
+
+// envLines returns the fields of the json output as shell variables.
+func envLines(prefix string, record reflect.Value) []string {
+	var lines []string
+	recordType := record.Type()
+	for i := 0; i < recordType.NumField(); i++ {
+		tag := strings.Split(recordType.Field(i).Tag.Get("json"), ",")
+		field := record.Field(i)
+		if len(tag) > 1 && tag[1] == "omitempty" && field.IsZero() {
+			continue
+		}
+		var value string
+		switch field.Kind() {
+		case reflect.Ptr:
+			value = fmt.Sprint(field.Elem().Interface())
+		case reflect.Slice:
+			value = strings.Join(field.Interface().([]string), ",")
+		default:
+			value = fmt.Sprint(field.Interface())
+		}
+		lines = append(lines, prefix+strings.ToUpper(tag[0])+"="+shellQuote(value))
+	}
+	return lines
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:+,@=-]+$`)
+
+func shellQuote(value string) string {
+	if shellSafe.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// existingTags returns the tag names of the repository, bump compares them to tell new tags from existing ones.
+func existingTags(repo *git.Repository) (map[string]bool, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		existing[ref.Name().Short()] = true
+		return nil
+	})
+	return existing, err
+}
+
+// bumpedRecord describes the version returned by bump with Created and Previous.
+func bumpedRecord(repo *git.Repository, rootFlags *RootFlags, version *semver.Version, existing map[string]bool) (versionRecord, error) {
+	record, err := headVersionRecord(repo, rootFlags.tagPrefix, version)
+	if err != nil {
+		return record, err
+	}
+	if record.Previous, err = previousRelease(repo, rootFlags, version); err != nil {
+		return record, err
+	}
+	created := record.Tag == "" || !existing[record.Tag]
+	record.Created = &created
+	return record, nil
+}
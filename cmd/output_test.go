@@ -0,0 +1,116 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sky-uk/vergo/bump"
+	. "github.com/sky-uk/vergo/cmd"
+	vergo "github.com/sky-uk/vergo/git"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/sky-uk/vergo/release"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+)
+
+func TestGetShouldWriteStructuredOutput(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "first")
+	tagger := &object.Signature{Name: "test", Email: "test@test.com", When: time.Date(2026, 1, 12, 10, 0, 0, 0, time.UTC)}
+	assert.Nil(t, vergo.CreateTagWithMessage(repo, "0.1.0", "app-", "release", tagger, false))
+	head, err := repo.Head()
+	assert.Nil(t, err)
+
+	expected := map[string]interface{}{
+		"version":   "0.1.0",
+		"prefix":    "app-",
+		"tag":       "app-0.1.0",
+		"commit":    head.Hash().String(),
+		"annotated": true,
+		"tagger":    "test <test@test.com>",
+		"date":      "2026-01-12T10:00:00Z",
+	}
+	{
+		cmd, buffer := makeGet(t, useTestDefaultForCurrentVersion)
+		cmd.SetArgs([]string{"get", "latest-release", "--repository-location", tempDir, "-t", "app", "-o", "json"})
+		assert.Nil(t, cmd.Execute())
+		var record map[string]interface{}
+		assert.Nil(t, json.Unmarshal(buffer.Bytes(), &record))
+		assert.Equal(t, expected, record)
+	}
+	{
+		cmd, buffer := makeGet(t, useTestDefaultForCurrentVersion)
+		cmd.SetArgs([]string{"get", "latest-release", "--repository-location", tempDir, "-t", "app", "-o", "yaml"})
+		assert.Nil(t, cmd.Execute())
+		var record map[string]interface{}
+		assert.Nil(t, yaml.Unmarshal(buffer.Bytes(), &record))
+		assert.Equal(t, expected, record)
+	}
+	DoCommit(t, repo, "second")
+	second, err := repo.Head()
+	assert.Nil(t, err)
+	_, err = repo.CreateTag("app-0.2", second.Hash(), nil)
+	assert.Nil(t, err)
+	{
+		cmd, buffer, _ := makeVergo(t)
+		cmd.SetArgs([]string{"list", "--repository-location", tempDir, "-t", "app", "-o", "env"})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "VERGO_0_VERSION=0.2.0\n"+
+			"VERGO_0_PREFIX=app-\n"+
+			"VERGO_0_TAG=app-0.2\n"+
+			"VERGO_0_COMMIT="+second.Hash().String()+"\n"+
+			"VERGO_0_ANNOTATED=false\n"+
+			"VERGO_0_DATE=2017-05-04T00:03:43+02:00\n"+
+			"VERGO_1_VERSION=0.1.0\n"+
+			"VERGO_1_PREFIX=app-\n"+
+			"VERGO_1_TAG=app-0.1.0\n"+
+			"VERGO_1_COMMIT="+head.Hash().String()+"\n"+
+			"VERGO_1_ANNOTATED=true\n"+
+			"VERGO_1_TAGGER='test <test@test.com>'\n"+
+			"VERGO_1_DATE=2026-01-12T10:00:00Z\n", readBuffer(t, buffer))
+	}
+	{
+		cmd, _ := makeGet(t, useTestDefaultForCurrentVersion)
+		cmd.SetArgs([]string{"get", "latest-release", "--repository-location", tempDir, "-t", "untagged", "-o", "json"})
+		assert.ErrorIs(t, cmd.Execute(), vergo.ErrNoTagFound)
+	}
+	cmd, _ := makeGet(t, useTestDefaultForCurrentVersion)
+	cmd.SetArgs([]string{"get", "latest-release", "--repository-location", tempDir, "-o", "xml"})
+	assert.ErrorIs(t, cmd.Execute(), ErrInvalidArg)
+}
+
+func TestBumpShouldTellCreatedTagsInStructuredOutput(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "first")
+	for _, created := range []bool{true, false} {
+		cmd, buffer := makeBumpFunc(t, bump.Bump)
+		cmd.SetArgs([]string{"bump", "minor", "--repository-location", tempDir, "-t", "app", "-o", "json", "--lightweight-tag"})
+		assert.Nil(t, cmd.Execute())
+		var record map[string]interface{}
+		assert.Nil(t, json.Unmarshal(buffer.Bytes(), &record))
+		assert.Equal(t, "app-0.1.0", record["tag"])
+		assert.Equal(t, false, record["annotated"])
+		assert.Equal(t, created, record["created"])
+	}
+}
+
+func TestCheckShouldWriteStructuredOutput(t *testing.T) {
+	repo, tempDir := PersistentRepository(t)
+	DoCommit(t, repo, "api/first")
+	tagHead(t, repo, "app-0.1.0")
+
+	cmd, buffer := makeCheck(t)
+	cmd.SetArgs([]string{"check", "changed", "--repository-location", tempDir, "-t", "app", "-o", "json", "--paths", "api"})
+	assert.ErrorIs(t, cmd.Execute(), release.ErrNoChanges)
+	var record map[string]interface{}
+	assert.Nil(t, json.NewDecoder(buffer).Decode(&record))
+	assert.Equal(t, "changed", record["check"])
+	assert.Equal(t, false, record["passed"])
+
+	DoCommit(t, repo, "api/second")
+	cmd, buffer = makeCheck(t)
+	cmd.SetArgs([]string{"check", "changed", "--repository-location", tempDir, "-t", "app", "-o", "env", "--paths", "api"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "VERGO_CHECK=changed\nVERGO_PASSED=true\nVERGO_CHANGED=api/second\n", readBuffer(t, buffer))
+}
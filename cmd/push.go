@@ -2,7 +2,7 @@ package cmd
 
 import (
 	"github.com/go-git/go-git/v5"
-	vergo "github.com/sky-uk/vergo/git"
+	"github.com/sky-uk/vergo/client"
 	"github.com/spf13/cobra"
 )
 
@@ -20,15 +20,12 @@ func PushCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			ref, err := vergo.LatestRef(repo, rootFlags.tagPrefix)
+			project := newProject(repo, rootFlags, client.Backend{})
+			version, err := project.Latest(cmd.Context())
 			if err != nil {
 				return err
 			}
-			err = vergo.PushTag(repo, ref.Version.String(), rootFlags.tagPrefix, rootFlags.remote, rootFlags.dryRun, rootFlags.disableStrictHostChecking, rootFlags.tokenEnvVarKey)
-			if err != nil {
-				return err
-			}
-			return nil
+			return project.Push(cmd.Context(), version)
 		},
 	}
 }
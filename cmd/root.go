@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/sky-uk/vergo/bump"
+	"github.com/sky-uk/vergo/config"
 	vergo "github.com/sky-uk/vergo/git"
 	"github.com/sky-uk/vergo/release"
 	"github.com/spf13/cobra"
 	"os"
+	"os/signal"
+	"strings"
 )
 
 func RootCmd() *cobra.Command {
@@ -19,26 +24,52 @@ func RootCmd() *cobra.Command {
 	rootCmd.PersistentFlags().StringP(remoteName, "r", "origin", "remote name for push")
 	rootCmd.PersistentFlags().StringP(tagPrefix, "t", "", "version prefix")
 	rootCmd.PersistentFlags().StringP(repositoryLocation, "l", ".", "repository location")
+	rootCmd.PersistentFlags().String(configFile, "", "config file, default "+config.FileName+" at the root of the repository")
+	rootCmd.PersistentFlags().String(project, "", "project of the config file, default the project with the tag prefix")
 	rootCmd.PersistentFlags().String(logLevel, "Info", "set log level")
+	rootCmd.PersistentFlags().String(logFile, "", "file the logs and the push progress are appended to, default stderr")
 	rootCmd.PersistentFlags().BoolP(strictHostChecking, "d", false, "disable strict host checking for git. should only be enabled on ci.")
 	rootCmd.PersistentFlags().StringP(tokenEnvVarKey, "k", "GH_TOKEN", "environment variable key to use for lookup when deciding if token based git auth should be used")
 	rootCmd.PersistentFlags().Bool(dryRun, false, "dry run")
 	rootCmd.PersistentFlags().Bool(nearestRelease, false, "use nearest tag in the commit history, default use highest tag")
 	rootCmd.PersistentFlags().StringSlice(versionedBranchNames, []string{"master", "main"},
 		"names of the main working branches")
+	rootCmd.PersistentFlags().StringSlice(versionLinePatterns, release.DefaultVersionLinePatterns,
+		"branch patterns of maintenance branches with {major} and optional {minor} e.g. release/{major}.{minor}, "+
+			"bumps on them stay inside the version line")
+	rootCmd.PersistentFlags().StringSlice(paths, nil, "path globs of the project e.g. services/api/**, default the whole repository")
+	rootCmd.PersistentFlags().StringSlice(ignorePaths, nil, "path globs ignored when detecting changes e.g. **/*.md,docs")
+	rootCmd.PersistentFlags().StringSlice(versionGroup, nil, "tag prefixes sharing the version of the tag prefix, bumping any of them "+
+		"tags every member with the next version of the highest release across the group")
 	rootCmd.PersistentFlags().BoolP(withPrefix, "p", false, "returns version with prefix")
+	rootCmd.PersistentFlags().StringP(output, "o", textOutput, "output format ["+strings.Join(outputFormats, ",")+"], "+
+		"json, yaml and env describe the tags of get, list, bump and check")
+	rootCmd.PersistentFlags().String(versionFormat, "", "go template of the versions printed by get, list, bump and mark-next "+
+		"e.g. {{.Major}}.{{.Minor}}, fields: Version, Major, Minor, Patch, Prerelease, Metadata, Prefix, Tag, Commit, "+
+		"ShortCommit, Branch and Distance, default {{.Version}} or {{.Prefix}}{{.Version}} with --with-prefix")
+	rootCmd.PersistentFlags().String(initialVersion, "", "version of the first release when there is no tag, default 0.1.0 "+
+		"and current-version returns it as a SNAPSHOT, default 0.0.0-SNAPSHOT")
 	return rootCmd
 }
 
 type RootFlags struct {
 	remote, tagPrefix, tagPrefixRaw, repositoryLocation           string
-	tokenEnvVarKey                                                string
+	tokenEnvVarKey, initialVersion, output, format                string
 	logLevel                                                      log.Level
 	withPrefix, dryRun, nearestRelease, disableStrictHostChecking bool
-	versionedBranches                                             []string
+	versionedBranches, versionLines, paths, ignorePaths           []string
+	versionGroup                                                  []string
 }
 
+// readRootFlags reads the flags, flags not given on the command line are set from the environment or the config file.
 func readRootFlags(cmd *cobra.Command) (*RootFlags, error) {
+	if err := applyConfig(cmd); err != nil {
+		return nil, err
+	}
+	return parseRootFlags(cmd)
+}
+
+func parseRootFlags(cmd *cobra.Command) (*RootFlags, error) {
 	remote, err := cmd.Flags().GetString(remoteName)
 	if err != nil {
 		return nil, err
@@ -47,6 +78,22 @@ func readRootFlags(cmd *cobra.Command) (*RootFlags, error) {
 	if err != nil {
 		return nil, err
 	}
+	versionLines, err := cmd.Flags().GetStringSlice(versionLinePatterns)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := cmd.Flags().GetStringSlice(paths)
+	if err != nil {
+		return nil, err
+	}
+	ignorePaths, err := cmd.Flags().GetStringSlice(ignorePaths)
+	if err != nil {
+		return nil, err
+	}
+	versionGroup, err := cmd.Flags().GetStringSlice(versionGroup)
+	if err != nil {
+		return nil, err
+	}
 	dryRun, err := cmd.Flags().GetBool(dryRun)
 	if err != nil {
 		return nil, err
@@ -71,6 +118,13 @@ func readRootFlags(cmd *cobra.Command) (*RootFlags, error) {
 	if err != nil {
 		return nil, err
 	}
+	logFilePath, err := cmd.Flags().GetString(logFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := setLogOutput(cmd, logFilePath); err != nil {
+		return nil, err
+	}
 	disableStrictHostChecking, err := cmd.Flags().GetBool(strictHostChecking)
 	if err != nil {
 		return nil, err
@@ -79,6 +133,28 @@ func readRootFlags(cmd *cobra.Command) (*RootFlags, error) {
 	if err != nil {
 		return nil, err
 	}
+	initialVersion, err := cmd.Flags().GetString(initialVersion)
+	if err != nil {
+		return nil, err
+	}
+	outputParam, err := cmd.Flags().GetString(output)
+	if err != nil {
+		return nil, err
+	}
+	outputFormat, err := parseOutputFormat(outputParam)
+	if err != nil {
+		return nil, err
+	}
+	format, err := cmd.Flags().GetString(versionFormat)
+	if err != nil {
+		return nil, err
+	}
+	if format != "" && outputFormat != textOutput {
+		return nil, fmt.Errorf("%w : --%s cannot be combined with --%s %s", ErrInvalidArg, versionFormat, output, outputFormat)
+	}
+	if _, err := versionTemplate(&RootFlags{format: format}); err != nil {
+		return nil, err
+	}
 	logLevel, err := log.ParseLevel(logLevelParam)
 	if err != nil {
 		log.WithError(err).Errorln("invalid log level, using INFO instead")
@@ -89,6 +165,10 @@ func readRootFlags(cmd *cobra.Command) (*RootFlags, error) {
 	return &RootFlags{
 		remote:                    remote,
 		versionedBranches:         versionedBranches,
+		versionLines:              versionLines,
+		paths:                     paths,
+		ignorePaths:               ignorePaths,
+		versionGroup:              sanitiseTagPrefixes(versionGroup),
 		tagPrefix:                 sanitiseTagPrefix(prefix),
 		tagPrefixRaw:              prefix,
 		repositoryLocation:        repositoryLocation,
@@ -98,18 +178,49 @@ func readRootFlags(cmd *cobra.Command) (*RootFlags, error) {
 		withPrefix:                withPrefix,
 		disableStrictHostChecking: disableStrictHostChecking,
 		tokenEnvVarKey:            tokenEnvVarKey,
+		initialVersion:            initialVersion,
+		output:                    outputFormat,
+		format:                    format,
 	}, nil
 }
 
+//nolint:gochecknoglobals
+var openLogFile *os.File
+
+// setLogOutput routes the logs to the log file or the error writer, keeping the output for the result.
+func setLogOutput(cmd *cobra.Command, file string) error {
+	if openLogFile != nil {
+		_ = openLogFile.Close()
+		openLogFile = nil
+	}
+	if file == "" {
+		log.SetOutput(cmd.ErrOrStderr())
+		return nil
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("%w : --%s %s", ErrInvalidArg, logFile, err)
+	}
+	openLogFile = f
+	log.SetOutput(f)
+	return nil
+}
+
 // Execute executes the root command.
 func Execute() error {
 	var rootCmd = RootCmd()
-	rootCmd.AddCommand(BumpCmd(bump.Bump, vergo.PushTag))
+	rootCmd.AddCommand(BumpCmd(bump.Bump, bump.Set, nil, nil))
 	rootCmd.AddCommand(GetCmd(vergo.LatestRef, vergo.PreviousRef, vergo.CurrentVersion))
+	rootCmd.AddCommand(MarkNextCmd(bump.MarkNext, nil))
 	rootCmd.AddCommand(PushCmd())
 	rootCmd.AddCommand(ListCmd(vergo.ListRefs))
 	rootCmd.AddCommand(CheckCmd(release.SkipHintPresent, release.ValidateHEAD, release.IncrementHint))
+	rootCmd.AddCommand(ChangelogCmd())
+	rootCmd.AddCommand(VerifyCmd())
 	rootCmd.AddCommand(ShowCmd())
 	rootCmd.AddCommand(VersionCmd())
-	return rootCmd.Execute()
+	// an interrupt cancels the context of the commands and their pushes
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return rootCmd.ExecuteContext(ctx)
 }
@@ -0,0 +1,160 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sky-uk/vergo/bump"
+	. "github.com/sky-uk/vergo/cmd"
+	vergo "github.com/sky-uk/vergo/git"
+	. "github.com/sky-uk/vergo/internal-test"
+	"github.com/sky-uk/vergo/release"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeVergo returns the root command with the commands of Execute and separate output and error writers.
+func makeVergo(t *testing.T) (*cobra.Command, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	cmd := RootCmd()
+	cmd.AddCommand(BumpCmd(bump.Bump, bump.Set, nil, nil))
+	cmd.AddCommand(GetCmd(vergo.LatestRef, vergo.PreviousRef, vergo.CurrentVersion))
+	cmd.AddCommand(MarkNextCmd(bump.MarkNext, nil))
+	cmd.AddCommand(PushCmd())
+	cmd.AddCommand(ListCmd(vergo.ListRefs))
+	cmd.AddCommand(CheckCmd(release.SkipHintPresent, release.ValidateHEAD, release.IncrementHint))
+	cmd.AddCommand(ChangelogCmd())
+	cmd.AddCommand(VerifyCmd())
+	cmd.AddCommand(ShowCmd())
+	cmd.AddCommand(VersionCmd())
+	stdout, stderr := bytes.NewBufferString(""), bytes.NewBufferString("")
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	return cmd, stdout, stderr
+}
+
+// repositoryWithRemote returns a repository whose origin remote is a bare repository, the pushes authenticate
+// with a token which the file transport ignores.
+func repositoryWithRemote(t *testing.T) (*git.Repository, string, *git.Repository) {
+	t.Helper()
+	repo, tempDir := PersistentRepository(t)
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	remote, err := git.PlainInit(remoteDir, true)
+	assert.Nil(t, err)
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{remoteDir}})
+	assert.Nil(t, err)
+	t.Setenv("SSH_AUTH_SOCK", "")
+	t.Setenv("GH_TOKEN", "token")
+	return repo, tempDir, remote
+}
+
+func TestCommandsShouldOnlyWriteTheResultToStdout(t *testing.T) {
+	repo, tempDir, remote := repositoryWithRemote(t)
+	DoCommit(t, repo, "api/first")
+	first, err := repo.Head()
+	assert.Nil(t, err)
+	DoCommitWithMessage(t, repo, "api/second", "feat: second")
+	head, err := repo.Head()
+	assert.Nil(t, err)
+
+	tests := []struct {
+		args     []string
+		expected string
+		err      error
+	}{
+		{args: []string{"bump", "minor", "-t", "app", "--push-tag"}, expected: "0.1.0"},
+		{args: []string{"bump", "minor", "-t", "app", "--push-tag", "--format", "{{.Tag}}"}, expected: "app-0.1.0"},
+		{args: []string{"bump", "set", "0.1.0", "-t", "app", "--push-tag"}, expected: "0.1.0"},
+		{args: []string{"bump", "minor", "--all", "--push-tag", "--dry-run"}, expected: "app 0.1.0 unchanged"},
+		{args: []string{"push", "-t", "app"}, expected: ""},
+		{args: []string{"mark-next", "0.2.0", "-t", "app", "--push-tag"}, expected: "0.2.0"},
+		{args: []string{"get", "latest-release", "-t", "app"}, expected: "0.1.0"},
+		{args: []string{"get", "current-version", "-t", "app"}, expected: "0.1.0"},
+		{args: []string{"list", "-t", "app"}, expected: "0.1.0\n"},
+		{args: []string{"check", "release", "-t", "app"}, expected: ""},
+		{args: []string{"check", "changed", "-t", "app", "--paths", "api"}, expected: "", err: release.ErrNoChanges},
+		{args: []string{"changelog", "-t", "app"}, expected: "## [app-0.1.0] - 2017-05-04\n\n### Features\n\n- second (" +
+			head.Hash().String()[:7] + ")\n\n### Other Changes\n\n- api/first (" + first.Hash().String()[:7] + ")\n"},
+		{args: []string{"show", "parents", head.Hash().String()}, expected: first.Hash().String() + "   api/first\n"},
+		{args: []string{"version", "simple"}, expected: ""},
+	}
+	for _, test := range tests {
+		cmd, stdout, _ := makeVergo(t)
+		cmd.SetArgs(append(test.args, "--repository-location", tempDir, "--log-level", "trace"))
+		err := cmd.Execute()
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err, test.args)
+		} else {
+			assert.Nil(t, err, test.args)
+		}
+		assert.Equal(t, test.expected, readBuffer(t, stdout), test.args)
+	}
+	for _, tag := range []string{"app-0.1.0", vergo.MarkerPrefix("app-") + "0.2.0"} {
+		_, err := remote.Tag(tag)
+		assert.Nil(t, err, tag)
+	}
+}
+
+func TestVerifyShouldWriteErrorsToStderr(t *testing.T) {
+	repo, tempDir, _ := repositoryWithRemote(t)
+	DoCommit(t, repo, "first")
+	tagHead(t, repo, "app-0.1.0")
+	allowedSigners := filepath.Join(t.TempDir(), "allowed_signers")
+	assert.Nil(t, os.WriteFile(allowedSigners, nil, 0600))
+
+	cmd, stdout, stderr := makeVergo(t)
+	cmd.SetArgs([]string{"verify", "--repository-location", tempDir, "-t", "app", "--log-level", "trace",
+		"--allowed-signers", allowedSigners})
+	assert.ErrorIs(t, cmd.Execute(), vergo.ErrUnsignedTag)
+	assert.Equal(t, "", readBuffer(t, stdout))
+	assert.Contains(t, readBuffer(t, stderr), "Error: tag is not signed")
+}
+
+func TestLogFileShouldReceiveTheLogs(t *testing.T) {
+	repo, tempDir, _ := repositoryWithRemote(t)
+	DoCommit(t, repo, "first")
+	logFile := filepath.Join(t.TempDir(), "vergo.log")
+
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		cmd, stdout, stderr := makeVergo(t)
+		cmd.SetArgs([]string{"bump", "set", version, "--push-tag", "--repository-location", tempDir, "-t", "app",
+			"--log-level", "debug", "--log-file", logFile})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, version, readBuffer(t, stdout))
+		assert.Equal(t, "", readBuffer(t, stderr))
+	}
+	logs := readFile(t, logFile)
+	assert.Contains(t, logs, "Set tag app-0.1.0")
+	assert.Contains(t, logs, "Pushing tags: [app-0.2.0]")
+}
+
+func TestCancelledCommandsShouldNotPush(t *testing.T) {
+	repo, tempDir, remote := repositoryWithRemote(t)
+	DoCommit(t, repo, "first")
+	tagHead(t, repo, "app-0.1.0")
+	DoCommit(t, repo, "second")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, args := range [][]string{
+		{"bump", "minor", "--all", "--push-tag"},
+		{"bump", "minor", "-t", "app", "--push-tag"},
+		{"bump", "set", "0.3.0", "-t", "api", "--push-tag"},
+		{"mark-next", "0.4.0", "-t", "app", "--push-tag"},
+	} {
+		cmd, _, _ := makeVergo(t)
+		cmd.SetArgs(append(args, "--repository-location", tempDir))
+		assert.ErrorIs(t, cmd.ExecuteContext(ctx), context.Canceled, args)
+	}
+	tags, err := remote.Tags()
+	assert.Nil(t, err)
+	assert.Nil(t, tags.ForEach(func(ref *plumbing.Reference) error {
+		t.Errorf("unexpected tag %s", ref.Name())
+		return nil
+	}))
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/sky-uk/vergo/changelog"
+	"github.com/sky-uk/vergo/client"
+	vergo "github.com/sky-uk/vergo/git"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// DefaultTagMessage is the message template of the annotated tags
+const DefaultTagMessage = "Release {{ .Tag }}"
+
+type tagFlags struct {
+	message, messageFile                                  string
+	signingKey, signingFormat, signingPassphraseEnvVarKey string
+	lightweight                                           bool
+}
+
+func addTagFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool(lightweightTag, false, "create lightweight tags instead of annotated tags")
+	cmd.PersistentFlags().String(tagMessage, DefaultTagMessage, "go template of the annotated tag message, "+
+		"fields: Version, TagPrefix, Tag, BuildURL and Changelog")
+	cmd.PersistentFlags().String(tagMessageFile, "", "go template file of the annotated tag message, overrides --message")
+	cmd.PersistentFlags().String(signingKey, "", "signs the annotated tags with the armored OpenPGP private key file, "+
+		"or the ssh agent key of the public key file with --signing-format ssh")
+	cmd.PersistentFlags().String(signingFormat, vergo.OpenPGPFormat, "signature format ["+vergo.OpenPGPFormat+","+vergo.SSHFormat+"]")
+	cmd.PersistentFlags().String(signingPassphraseEnvVarKey, "SIGNING_KEY_PASSPHRASE",
+		"environment variable key of the passphrase of the OpenPGP private key")
+}
+
+func readTagFlags(cmd *cobra.Command) (*tagFlags, error) {
+	lightweight, err := cmd.Flags().GetBool(lightweightTag)
+	if err != nil {
+		return nil, err
+	}
+	message, err := cmd.Flags().GetString(tagMessage)
+	if err != nil {
+		return nil, err
+	}
+	messageFile, err := cmd.Flags().GetString(tagMessageFile)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := cmd.Flags().GetString(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	signingFormat, err := cmd.Flags().GetString(signingFormat)
+	if err != nil {
+		return nil, err
+	}
+	signingPassphraseEnvVarKey, err := cmd.Flags().GetString(signingPassphraseEnvVarKey)
+	if err != nil {
+		return nil, err
+	}
+	return &tagFlags{
+		message:                    message,
+		messageFile:                messageFile,
+		signingKey:                 signingKey,
+		signingFormat:              signingFormat,
+		signingPassphraseEnvVarKey: signingPassphraseEnvVarKey,
+		lightweight:                lightweight,
+	}, nil
+}
+
+// annotate returns the options of the tags, annotated unless lightweight tags are requested.
+func annotate(repo *git.Repository, rootFlags *RootFlags, tagFlags *tagFlags) (client.TagOptions, error) {
+	var options client.TagOptions
+	if tagFlags.lightweight {
+		if tagFlags.signingKey != "" {
+			return options, fmt.Errorf("%w : --%s requires annotated tags", ErrInvalidArg, signingKey)
+		}
+		return options, nil
+	}
+	options.Tagger = vergo.Signature(repo)
+	options.Message = func(version semver.Version) (string, error) {
+		return renderTagMessage(repo, version, rootFlags, tagFlags)
+	}
+	if tagFlags.signingKey == "" {
+		return options, nil
+	}
+	signer, err := tagSigner(tagFlags)
+	if err != nil {
+		return options, err
+	}
+	options.Signer = signer
+	return options, nil
+}
+
+func tagSigner(tagFlags *tagFlags) (vergo.TagSigner, error) {
+	key, err := os.ReadFile(tagFlags.signingKey)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(tagFlags.signingFormat) {
+	case vergo.OpenPGPFormat, "gpg":
+		return vergo.NewOpenPGPSigner(bytes.NewReader(key), []byte(os.Getenv(tagFlags.signingPassphraseEnvVarKey)))
+	case vergo.SSHFormat:
+		return vergo.NewSSHAgentSigner(key)
+	default:
+		return nil, fmt.Errorf("%w : %s", vergo.ErrInvalidSigningFormat, tagFlags.signingFormat)
+	}
+}
+
+// tagMessageData are the fields of the tag message template.
+type tagMessageData struct {
+	Version, TagPrefix, Tag, BuildURL string
+	changelog                         func() (string, error)
+}
+
+// Changelog returns the markdown changelog since the latest release, only generated when the template uses it.
+func (d tagMessageData) Changelog() (string, error) {
+	return d.changelog()
+}
+
+func renderTagMessage(repo *git.Repository, version semver.Version, rootFlags *RootFlags, tagFlags *tagFlags) (string, error) {
+	text := tagFlags.message
+	if tagFlags.messageFile != "" {
+		content, err := os.ReadFile(tagFlags.messageFile)
+		if err != nil {
+			return "", err
+		}
+		text = string(content)
+	}
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w : message %s", ErrInvalidArg, err)
+	}
+	data := tagMessageData{
+		Version:   version.String(),
+		TagPrefix: rootFlags.tagPrefix,
+		Tag:       rootFlags.tagPrefix + version.String(),
+		BuildURL:  ciBuildURL(),
+		changelog: func() (string, error) {
+			releaseLog, err := releaseChangelog(repo, version, rootFlags)
+			if err != nil {
+				return "", err
+			}
+			var markdown bytes.Buffer
+			err = changelog.RenderTemplate(&markdown, releaseLog, changelog.FileTemplate)
+			return markdown.String(), err
+		},
+	}
+	var message bytes.Buffer
+	if err := tmpl.Execute(&message, data); err != nil {
+		return "", fmt.Errorf("%w : message %s", ErrInvalidArg, err)
+	}
+	return message.String(), nil
+}
+
+// ciBuildURL returns the URL of the CI build running vergo, empty outside a known CI.
+func ciBuildURL() string {
+	server, repository, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if server != "" && repository != "" && runID != "" {
+		return strings.TrimSuffix(server, "/") + "/" + repository + "/actions/runs/" + runID
+	}
+	for _, key := range []string{"CI_JOB_URL", "BUILD_URL", "CIRCLE_BUILD_URL", "BUILDKITE_BUILD_URL"} {
+		if url := os.Getenv(key); url != "" {
+			return url
+		}
+	}
+	return ""
+}
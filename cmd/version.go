@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"fmt"
 	"github.com/thoas/go-funk"
 
 	"github.com/spf13/cobra"
@@ -25,15 +24,15 @@ func VersionCmd() *cobra.Command {
 		Args:      cobra.OnlyValidArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			if snapshot == "true" {
-				fmt.Println("This is a SNAPSHOT build")
+				cmd.PrintErrln("This is a SNAPSHOT build")
 			}
 			if funk.ContainsString(args, "simple") {
-				fmt.Print(version) //nolint
+				cmd.Print(version)
 			} else {
-				fmt.Printf("version: %s\n", version) //nolint
-				fmt.Printf("commit : %s\n", commit)  //nolint
-				fmt.Printf("date: %s\n", date)       //nolint
-				fmt.Printf("builtBy: %s\n", builtBy) //nolint
+				cmd.Printf("version: %s\n", version)
+				cmd.Printf("commit : %s\n", commit)
+				cmd.Printf("date: %s\n", date)
+				cmd.Printf("builtBy: %s\n", builtBy)
 			}
 		},
 	}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FileName is the config file looked up at the root of the repository worktree
+	FileName = ".vergo.yaml"
+	// EnvPrefix prefixes the environment variables overriding flags e.g. VERGO_TAG_PREFIX for --tag-prefix
+	EnvPrefix = "VERGO_"
+)
+
+var (
+	ErrInvalidConfig   = errors.New("invalid config")
+	ErrUnknownProject  = errors.New("unknown project")
+	ErrAmbiguousPrefix = errors.New("tag prefix matches several projects")
+)
+
+// Settings are the flag values of a config file, every key is the name of the flag it sets.
+type Settings struct {
+	TagPrefix                  *string  `yaml:"tag-prefix"`
+	RemoteName                 *string  `yaml:"remote-name"`
+	VersionedBranchNames       []string `yaml:"versioned-branch-names"`
+	VersionLinePatterns        []string `yaml:"version-line-patterns"`
+	NearestRelease             *bool    `yaml:"nearest-release"`
+	InitialVersion             *string  `yaml:"initial-version"`
+	Paths                      []string `yaml:"paths"`
+	IgnorePaths                []string `yaml:"ignore-paths"`
+	VersionGroup               []string `yaml:"version-group"`
+	TokenEnvVarKey             *string  `yaml:"token-env-var-key"`
+	DisableStrictHost          *bool    `yaml:"disable-strict-host-check"`
+	SnapshotStrategy           *string  `yaml:"snapshot-strategy"`
+	SnapshotIncrement          *string  `yaml:"snapshot-increment"`
+	SnapshotTemplate           *string  `yaml:"snapshot-template"`
+	PreReleaseIdentifier       *string  `yaml:"pre-release-identifier"`
+	PreReleaseChannels         []string `yaml:"pre-release-channels"`
+	ConventionalCommits        *bool    `yaml:"conventional-commits"`
+	ScopeAsPrefix              *bool    `yaml:"scope-as-prefix"`
+	PropagationIncrement       *string  `yaml:"propagation-increment"`
+	UpdateChangelog            *bool    `yaml:"update-changelog"`
+	ChangelogFile              *string  `yaml:"changelog-file"`
+	ChangelogTemplate          *string  `yaml:"changelog-template"`
+	LightweightTag             *bool    `yaml:"lightweight-tag"`
+	Message                    *string  `yaml:"message"`
+	MessageFile                *string  `yaml:"message-file"`
+	SigningKey                 *string  `yaml:"signing-key"`
+	SigningFormat              *string  `yaml:"signing-format"`
+	SigningPassphraseEnvVarKey *string  `yaml:"signing-passphrase-env-var-key"`
+	Keyring                    []string `yaml:"keyring"`
+	AllowedSigners             []string `yaml:"allowed-signers"`
+	CIOutputs                  *bool    `yaml:"ci-outputs"`
+	CIDotenvFile               *string  `yaml:"ci-dotenv-file"`
+	// DependsOn are the projects, by name, tag prefix or path, whose releases are propagated to the project
+	DependsOn []string `yaml:"depends-on"`
+}
+
+// Values returns the settings which are set by flag name, DependsOn is not a flag and not included.
+func (s Settings) Values() map[string][]string {
+	values := make(map[string][]string)
+	setString := func(name string, value *string) {
+		if value != nil {
+			values[name] = []string{*value}
+		}
+	}
+	setBool := func(name string, value *bool) {
+		if value != nil {
+			values[name] = []string{strconv.FormatBool(*value)}
+		}
+	}
+	setSlice := func(name string, value []string) {
+		if value != nil {
+			values[name] = value
+		}
+	}
+	setString("tag-prefix", s.TagPrefix)
+	setString("remote-name", s.RemoteName)
+	setSlice("versioned-branch-names", s.VersionedBranchNames)
+	setSlice("version-line-patterns", s.VersionLinePatterns)
+	setBool("nearest-release", s.NearestRelease)
+	setString("initial-version", s.InitialVersion)
+	setSlice("paths", s.Paths)
+	setSlice("ignore-paths", s.IgnorePaths)
+	setSlice("version-group", s.VersionGroup)
+	setString("token-env-var-key", s.TokenEnvVarKey)
+	setBool("disable-strict-host-check", s.DisableStrictHost)
+	setString("snapshot-strategy", s.SnapshotStrategy)
+	setString("snapshot-increment", s.SnapshotIncrement)
+	setString("snapshot-template", s.SnapshotTemplate)
+	setString("pre-release-identifier", s.PreReleaseIdentifier)
+	setSlice("pre-release-channels", s.PreReleaseChannels)
+	setBool("conventional-commits", s.ConventionalCommits)
+	setBool("scope-as-prefix", s.ScopeAsPrefix)
+	setString("propagation-increment", s.PropagationIncrement)
+	setBool("update-changelog", s.UpdateChangelog)
+	setString("changelog-file", s.ChangelogFile)
+	setString("changelog-template", s.ChangelogTemplate)
+	setBool("lightweight-tag", s.LightweightTag)
+	setString("message", s.Message)
+	setString("message-file", s.MessageFile)
+	setString("signing-key", s.SigningKey)
+	setString("signing-format", s.SigningFormat)
+	setString("signing-passphrase-env-var-key", s.SigningPassphraseEnvVarKey)
+	setSlice("keyring", s.Keyring)
+	setSlice("allowed-signers", s.AllowedSigners)
+	setBool("ci-outputs", s.CIOutputs)
+	setString("ci-dotenv-file", s.CIDotenvFile)
+	return values
+}
+
+// Config is the repository config, project settings override the top level settings.
+type Config struct {
+	Settings `yaml:",inline"`
+	Projects map[string]Settings `yaml:"projects"`
+}
+
+// Parse parses the config, unknown keys are rejected.
+func Parse(data []byte) (*Config, error) {
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidConfig, err)
+	}
+	return &config, nil
+}
+
+// Load reads the config file, returns nil when the file does not exist.
+func Load(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", err, file)
+	}
+	return config, nil
+}
+
+// Discover loads FileName at the root of the repository worktree, returns nil when there is no config.
+func Discover(worktreeRoot string) (*Config, error) {
+	return Load(filepath.Join(worktreeRoot, FileName))
+}
+
+// ProjectNames returns the sorted names of the projects.
+func (c *Config) ProjectNames() []string {
+	names := make([]string, 0, len(c.Projects))
+	for name := range c.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Project returns the project selected by name or tag prefix, empty when none is selected.
+func (c *Config) Project(name, tagPrefix string) (string, error) {
+	if name != "" {
+		if _, ok := c.Projects[name]; !ok {
+			return "", fmt.Errorf("%w : %s, known projects: %s", ErrUnknownProject, name, strings.Join(c.ProjectNames(), ", "))
+		}
+		return name, nil
+	}
+	if tagPrefix == "" {
+		return "", nil
+	}
+	var matches []string
+	for _, projectName := range c.ProjectNames() {
+		if c.TagPrefix(projectName) == tagPrefix {
+			matches = append(matches, projectName)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%w : %s used by %s", ErrAmbiguousPrefix, tagPrefix, strings.Join(matches, ", "))
+	}
+}
+
+// TagPrefix returns the tag prefix of the project, its name when it has no tag-prefix.
+func (c *Config) TagPrefix(project string) string {
+	if prefix := c.Projects[project].TagPrefix; prefix != nil {
+		return *prefix
+	}
+	return project
+}
+
+// Values returns the flag values of the project merged over the top level settings, the tag prefix
+// of a project defaults to its name.
+func (c *Config) Values(project string) map[string][]string {
+	values := c.Settings.Values()
+	if project == "" {
+		return values
+	}
+	for name, value := range c.Projects[project].Values() {
+		values[name] = value
+	}
+	values["tag-prefix"] = []string{c.TagPrefix(project)}
+	return values
+}
+
+// EnvName returns the environment variable of the flag e.g. VERGO_TAG_PREFIX for tag-prefix.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
@@ -0,0 +1,103 @@
+package config_test
+
+import (
+	"github.com/sky-uk/vergo/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const repoConfig = `
+versioned-branch-names: [main, "release/*"]
+nearest-release: true
+token-env-var-key: CI_TOKEN
+projects:
+  api:
+    tag-prefix: api
+    paths: [services/api]
+    snapshot-strategy: distance
+    initial-version: 1.0.0
+  web:
+    tag-prefix: web
+    nearest-release: false
+`
+
+func TestShouldMergeProjectOverTopLevelSettings(t *testing.T) {
+	c, err := config.Parse([]byte(repoConfig))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"api", "web"}, c.ProjectNames())
+
+	values := c.Values("")
+	assert.Equal(t, map[string][]string{
+		"versioned-branch-names": {"main", "release/*"},
+		"nearest-release":        {"true"},
+		"token-env-var-key":      {"CI_TOKEN"},
+	}, values)
+
+	values = c.Values("api")
+	assert.Equal(t, []string{"api"}, values["tag-prefix"])
+	assert.Equal(t, []string{"services/api"}, values["paths"])
+	assert.Equal(t, []string{"distance"}, values["snapshot-strategy"])
+	assert.Equal(t, []string{"1.0.0"}, values["initial-version"])
+	assert.Equal(t, []string{"true"}, values["nearest-release"])
+
+	assert.Equal(t, []string{"false"}, c.Values("web")["nearest-release"])
+}
+
+func TestShouldSelectProject(t *testing.T) {
+	c, err := config.Parse([]byte(repoConfig))
+	assert.Nil(t, err)
+	tests := []struct {
+		name, tagPrefix, project string
+	}{
+		{name: "api", project: "api"},
+		{name: "api", tagPrefix: "web", project: "api"},
+		{tagPrefix: "web", project: "web"},
+		{tagPrefix: "other", project: ""},
+		{project: ""},
+	}
+	for _, test := range tests {
+		project, err := c.Project(test.name, test.tagPrefix)
+		assert.Nil(t, err)
+		assert.Equal(t, test.project, project)
+	}
+	_, err = c.Project("unknown", "")
+	assert.ErrorIs(t, err, config.ErrUnknownProject)
+
+	c, err = config.Parse([]byte("projects:\n  app: {}\n"))
+	assert.Nil(t, err)
+	project, err := c.Project("", "app")
+	assert.Nil(t, err)
+	assert.Equal(t, "app", project)
+	assert.Equal(t, "app", c.TagPrefix("app"))
+	assert.Equal(t, []string{"app"}, c.Values("app")["tag-prefix"])
+
+	c, err = config.Parse([]byte("projects:\n  a:\n    tag-prefix: app\n  b:\n    tag-prefix: app\n"))
+	assert.Nil(t, err)
+	_, err = c.Project("", "app")
+	assert.ErrorIs(t, err, config.ErrAmbiguousPrefix)
+}
+
+func TestShouldRejectInvalidConfig(t *testing.T) {
+	for _, invalid := range []string{"tag-prefx: app", "nearest-release: maybe", "projects: [api]"} {
+		_, err := config.Parse([]byte(invalid))
+		assert.ErrorIs(t, err, config.ErrInvalidConfig, invalid)
+	}
+	c, err := config.Parse(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, c.Values(""))
+}
+
+func TestShouldDiscoverConfig(t *testing.T) {
+	dir := t.TempDir()
+	c, err := config.Discover(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, c)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, config.FileName), []byte(repoConfig), 0600))
+	c, err = config.Discover(dir)
+	assert.Nil(t, err)
+	assert.Len(t, c.Projects, 2)
+	assert.Equal(t, "VERGO_TAG_PREFIX", config.EnvName("tag-prefix"))
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"time"
+)
+
+var ErrStagedChanges = errors.New("other changes are staged")
+
+// DefaultCommitter signs the release commits and tags when neither the environment nor the git config has a user.
+var DefaultCommitter = object.Signature{Name: "vergo", Email: "vergo@users.noreply.github.com"}
+
+// CommitFiles commits the files, relative to the worktree root, failing when other changes are staged.
+func CommitFiles(repo *gogit.Repository, message string, files ...string) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	committed := make(map[string]bool, len(files))
+	for _, file := range files {
+		committed[file] = true
+	}
+	for path, fileStatus := range status {
+		if !committed[path] && fileStatus.Staging != gogit.Unmodified && fileStatus.Staging != gogit.Untracked {
+			return fmt.Errorf("%w : %s", ErrStagedChanges, path)
+		}
+	}
+	for _, file := range files {
+		if _, err := worktree.Add(file); err != nil {
+			return err
+		}
+	}
+	signature := Signature(repo)
+	hash, err := worktree.Commit(message, &gogit.CommitOptions{Author: signature, Committer: signature})
+	if err != nil {
+		return err
+	}
+	log.Infof("Committed %s: %s", hash.String(), message)
+	return nil
+}
+
+// Signature returns the committer of vergo, from GIT_COMMITTER_NAME, the git config or DefaultCommitter.
+func Signature(repo *gogit.Repository) *object.Signature {
+	signature := DefaultCommitter
+	if cfg, err := repo.ConfigScoped(config.GlobalScope); err == nil && cfg.User.Name != "" {
+		signature.Name, signature.Email = cfg.User.Name, cfg.User.Email
+	}
+	if name, ok := os.LookupEnv("GIT_COMMITTER_NAME"); ok && name != "" {
+		signature.Name = name
+	}
+	if email, ok := os.LookupEnv("GIT_COMMITTER_EMAIL"); ok && email != "" {
+		signature.Email = email
+	}
+	signature.When = time.Now()
+	return &signature
+}
@@ -1,13 +1,16 @@
 package git
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/go-git/go-git/v5/plumbing/transport"
@@ -76,8 +79,92 @@ func TagExists(r *gogit.Repository, tag string) (bool, error) {
 	return found, err
 }
 
+// TagCommit returns the commit the tag points at, for both lightweight and annotated tags.
+func TagCommit(repo *gogit.Repository, ref *plumbing.Reference) (plumbing.Hash, error) {
+	tagObject, err := repo.TagObject(ref.Hash())
+	switch {
+	case err == nil:
+		return tagObject.Target, nil
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		return ref.Hash(), nil
+	default:
+		return plumbing.ZeroHash, err
+	}
+}
+
+// TagInfo describes a tag, Tagger is nil for lightweight tags.
+type TagInfo struct {
+	Name      string
+	Commit    plumbing.Hash
+	Annotated bool
+	Tagger    *object.Signature
+	Date      time.Time
+}
+
+// DescribeTag returns the commit, tagger and date of the tag, gogit.ErrTagNotFound when it does not exist.
+func DescribeTag(repo *gogit.Repository, name string) (TagInfo, error) {
+	ref, err := repo.Tag(name)
+	if err != nil {
+		return TagInfo{}, err
+	}
+	info := TagInfo{Name: name, Commit: ref.Hash()}
+	tagObject, err := repo.TagObject(ref.Hash())
+	switch {
+	case err == nil:
+		tagger := tagObject.Tagger
+		info.Commit, info.Annotated, info.Tagger, info.Date = tagObject.Target, true, &tagger, tagger.When
+		return info, nil
+	case !errors.Is(err, plumbing.ErrObjectNotFound):
+		return TagInfo{}, err
+	}
+	commit, err := repo.CommitObject(info.Commit)
+	if err != nil {
+		return TagInfo{}, err
+	}
+	info.Date = commit.Committer.When
+	return info, nil
+}
+
+// VersionRef returns the tag of the prefix releasing the version, tags written e.g. app-1.0 are found for 1.0.0.
+func VersionRef(repo *gogit.Repository, prefix string, version *semver.Version) (SemverRef, error) {
+	for _, name := range []string{prefix + version.Original(), prefix + version.String()} {
+		ref, err := repo.Tag(name)
+		if err == nil {
+			return SemverRef{Version: version, Ref: ref}, nil
+		}
+		if !errors.Is(err, gogit.ErrTagNotFound) {
+			return EmptyRef, err
+		}
+	}
+	refs, err := refsWithPrefix(repo, prefix)
+	if err != nil {
+		return EmptyRef, err
+	}
+	for _, ref := range refs {
+		if ref.Version.Equal(version) {
+			return ref, nil
+		}
+	}
+	return EmptyRef, fmt.Errorf("%w : %s%s", ErrNoTagFound, prefix, version.String())
+}
+
+// DefaultSignedTagMessage prefixes the tag name in the message of signed tags without message
+const DefaultSignedTagMessage = "Release "
+
+// TagOptions annotate the tags when the message is not blank, signed by Signer when set. Signed tags are
+// always annotated, with DefaultSignedTagMessage and the tag name when the message is blank.
+type TagOptions struct {
+	Message string
+	Tagger  *object.Signature
+	Signer  TagSigner
+}
+
 func CreateTagWithMessage(repo *gogit.Repository, version, prefix, message string,
 	tagger *object.Signature, dryRun bool) error {
+	return CreateTagWithOptions(repo, version, prefix, TagOptions{Message: message, Tagger: tagger}, dryRun)
+}
+
+func CreateTagWithOptions(repo *gogit.Repository, version, prefix string, options TagOptions, dryRun bool) error {
 	tag := prefix + version
 	found, err := TagExists(repo, tag)
 	if err != nil {
@@ -94,12 +181,18 @@ func CreateTagWithMessage(repo *gogit.Repository, version, prefix, message strin
 	if dryRun {
 		log.Infof("Dry run: create tag %v", tag)
 	} else {
-		if strings.TrimSpace(message) == "" {
+		switch {
+		case options.Signer != nil:
+			if strings.TrimSpace(options.Message) == "" {
+				options.Message = DefaultSignedTagMessage + tag
+			}
+			err = createSignedTag(repo, tag, h.Hash(), options)
+		case strings.TrimSpace(options.Message) == "":
 			_, err = repo.CreateTag(tag, h.Hash(), nil)
-		} else {
+		default:
 			_, err = repo.CreateTag(tag, h.Hash(), &gogit.CreateTagOptions{
-				Tagger:  tagger,
-				Message: message,
+				Tagger:  options.Tagger,
+				Message: options.Message,
 			})
 		}
 		if err != nil {
@@ -110,21 +203,128 @@ func CreateTagWithMessage(repo *gogit.Repository, version, prefix, message strin
 	return nil
 }
 
+// createSignedTag creates the annotated tag object signed by options.Signer and its reference.
+func createSignedTag(repo *gogit.Repository, name string, target plumbing.Hash, options TagOptions) error {
+	if options.Tagger == nil {
+		return fmt.Errorf("%w : tagger required", ErrInvalidSigningKey)
+	}
+	message := options.Message
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     *options.Tagger,
+		Message:    message,
+		TargetType: plumbing.CommitObject,
+		Target:     target,
+	}
+	unsigned := &plumbing.MemoryObject{}
+	if err := tag.EncodeWithoutSignature(unsigned); err != nil {
+		return err
+	}
+	reader, err := unsigned.Reader()
+	if err != nil {
+		return err
+	}
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if tag.PGPSignature, err = options.Signer.Sign(payload); err != nil {
+		return err
+	}
+	signed := repo.Storer.NewEncodedObject()
+	if err := tag.Encode(signed); err != nil {
+		return err
+	}
+	hash, err := repo.Storer.SetEncodedObject(signed)
+	if err != nil {
+		return err
+	}
+	return repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(name), hash))
+}
+
 func CreateTag(repo *gogit.Repository, version, prefix string, dryRun bool) error {
 	return CreateTagWithMessage(repo, version, prefix, "", nil, dryRun)
 }
 
+// CheckTagsAvailable returns gogit.ErrTagExists when the version is already tagged for one of the prefixes.
+func CheckTagsAvailable(repo *gogit.Repository, version string, prefixes []string) error {
+	for _, prefix := range prefixes {
+		found, err := TagExists(repo, prefix+version)
+		if err != nil {
+			return err
+		}
+		if found {
+			return fmt.Errorf("%w : %s", gogit.ErrTagExists, prefix+version)
+		}
+	}
+	return nil
+}
+
+// CreateTags tags HEAD with the version for every prefix, all or none of them.
+func CreateTags(repo *gogit.Repository, version string, prefixes []string, options TagOptions, dryRun bool) error {
+	if err := CheckTagsAvailable(repo, version, prefixes); err != nil {
+		return err
+	}
+	for i, prefix := range prefixes {
+		err := CreateTagWithOptions(repo, version, prefix, options, dryRun)
+		if err == nil {
+			continue
+		}
+		for _, created := range prefixes[:i] {
+			if dryRun {
+				break
+			}
+			if deleteErr := repo.DeleteTag(created + version); deleteErr != nil {
+				log.WithError(deleteErr).Errorf("Failed to delete tag %s", created+version)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
 type PushTagFunc func(
 	repo *gogit.Repository,
 	version, prefix, remote string,
 	dryRun bool, disableStrictHostChecking bool, tokenEnvVarKey string) error
 
 func PushTag(r *gogit.Repository, version, prefix, remote string, dryRun bool, disableStrictHostChecking bool, tokenEnvVarKey string) error {
-	tag := prefix + version
+	return PushTags(r, []string{prefix + version}, remote, dryRun, disableStrictHostChecking, tokenEnvVarKey)
+}
 
+type PushTagsFunc func(
+	repo *gogit.Repository,
+	tags []string, remote string,
+	dryRun bool, disableStrictHostChecking bool, tokenEnvVarKey string) error
+
+// PushTags pushes the tags to the remote in a single push.
+func PushTags(r *gogit.Repository, tags []string, remote string, dryRun bool, disableStrictHostChecking bool, tokenEnvVarKey string) error {
+	return PushTagsContext(context.Background(), r, tags, PushOptions{
+		Remote:                    remote,
+		DryRun:                    dryRun,
+		DisableStrictHostChecking: disableStrictHostChecking,
+		TokenEnvVarKey:            tokenEnvVarKey,
+	})
+}
+
+// PushOptions configure PushTagsContext.
+type PushOptions struct {
+	Remote string
+	DryRun bool
+	// DisableStrictHostChecking skips the known hosts check of ssh remotes, should only be set on CI
+	DisableStrictHostChecking bool
+	// TokenEnvVarKey is the environment variable of the token of https remotes, the ssh agent is used without it
+	TokenEnvVarKey string
+}
+
+// PushTagsContext pushes the tags to the remote in a single push, the context cancels the push.
+func PushTagsContext(ctx context.Context, r *gogit.Repository, tags []string, options PushOptions) error {
 	var auth transport.AuthMethod
 
-	if githubToken, ok := os.LookupEnv(tokenEnvVarKey); ok {
+	if githubToken, ok := os.LookupEnv(options.TokenEnvVarKey); ok {
 		log.Debug("Using Github Bearer Token Auth")
 		auth = &http.BasicAuth{
 			Username: "can-be-anything",
@@ -142,26 +342,30 @@ func PushTag(r *gogit.Repository, version, prefix, remote string, dryRun bool, d
 			_ = conn.Close()
 		}()
 
-		sshAuth := generateSshAuth(agentClient, disableStrictHostChecking)
+		sshAuth := generateSshAuth(agentClient, options.DisableStrictHostChecking)
 
 		auth = sshAuth
 	} else {
 		return ErrUndefinedAuth
 	}
 
-	log.Debugf("Pushing tag: %v", tag)
-	refSpec := config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag))
+	log.Debugf("Pushing tags: %v", tags)
+	refSpecs := make([]config.RefSpec, 0, len(tags))
+	for _, tag := range tags {
+		refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag)))
+	}
 	po := &gogit.PushOptions{
-		RemoteName: remote,
-		Progress:   os.Stdout,
-		RefSpecs:   []config.RefSpec{refSpec},
-		Auth:       auth,
+		RemoteName: options.Remote,
+		// the progress goes with the logs, stdout only receives the result of the command
+		Progress: log.StandardLogger().Out,
+		RefSpecs: refSpecs,
+		Auth:     auth,
 	}
 
-	if dryRun {
-		log.Infof("Dry run: push tag %v", tag)
+	if options.DryRun {
+		log.Infof("Dry run: push tags %v", strings.Join(tags, ", "))
 	} else {
-		err := r.Push(po)
+		err := r.PushContext(ctx, po)
 
 		if err != nil {
 			if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
@@ -192,6 +396,33 @@ func generateSshAuth(agentClient agent.ExtendedAgent, disableStrictHostChecking
 	return sshAuth
 }
 
+// TagPrefixes returns the sorted prefixes of the release tags e.g. app- for app-1.2.3.
+func TagPrefixes(repo *gogit.Repository) ([]string, error) {
+	re := regexp.MustCompile("^" + refTagPrefix + `(v|.+?-|.+/v)[0-9]+\.[0-9]+\.[0-9]+` +
+		`(-[0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*)?(\+[0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*)?$`)
+	tagRefs, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	prefixes := make(map[string]bool)
+	err = tagRefs.ForEach(func(t *plumbing.Reference) error {
+		match := re.FindStringSubmatch(t.Name().String())
+		if match != nil && !strings.HasPrefix(match[1], MarkerTagPrefix) {
+			prefixes[match[1]] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]string, 0, len(prefixes))
+	for prefix := range prefixes {
+		sorted = append(sorted, prefix)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
 func ListRefs(repo *gogit.Repository, prefix string, direction SortDirection, maxListSize int) ([]SemverRef, error) {
 	versions, err := refsWithPrefix(repo, prefix)
 	if err != nil {
@@ -230,6 +461,36 @@ func LatestRef(repo *gogit.Repository, prefix string) (SemverRef, error) {
 	return latestVersion, nil
 }
 
+// LatestRefInLine returns the highest tag of the prefix inside the version line.
+func LatestRefInLine(repo *gogit.Repository, prefix string, line release.VersionLine) (SemverRef, error) {
+	versions, err := reversedRefsWithPrefix(repo, prefix)
+	if err != nil {
+		return EmptyRef, err
+	}
+	for _, version := range versions {
+		if line.Contains(version.Version) {
+			log.Debugf("Latest version in line %s: %v\n", line.String(), version)
+			return version, nil
+		}
+	}
+	return EmptyRef, fmt.Errorf("%w : %s", ErrNoTagFound, line.String())
+}
+
+// LatestRefMatching returns the highest tag of the prefix whose version matches.
+func LatestRefMatching(repo *gogit.Repository, prefix string, match func(version *semver.Version) bool) (SemverRef, error) {
+	versions, err := reversedRefsWithPrefix(repo, prefix)
+	if err != nil {
+		return EmptyRef, err
+	}
+	for _, version := range versions {
+		if match(version.Version) {
+			log.Debugf("Latest matching version: %v\n", version)
+			return version, nil
+		}
+	}
+	return EmptyRef, fmt.Errorf("%w : %s", ErrNoTagFound, prefix)
+}
+
 func PreviousRef(repo *gogit.Repository, prefix string) (SemverRef, error) {
 	versions, err := reversedRefsWithPrefix(repo, prefix)
 	if err != nil {
@@ -296,6 +557,9 @@ func CurrentVersion(repo *gogit.Repository, prefix string, preRelease release.Pr
 		return EmptyRef, err
 	}
 	sortedTagRefs, err := reversedRefsWithPrefix(repo, prefix)
+	if errors.Is(err, ErrNoTagFound) {
+		return markerSnapshot(repo, prefix, head, preRelease)
+	}
 	if err != nil {
 		return EmptyRef, err
 	}
@@ -337,24 +601,58 @@ func CurrentVersion(repo *gogit.Repository, prefix string, preRelease release.Pr
 	if !preReleaseVersion.GreaterThan(latest.Version) {
 		return EmptyRef, fmt.Errorf("%w : %s", ErrPreReleaseVersion, "preReleaseVersion must create a greater version")
 	}
+	preReleaseVersion, err = WithMarker(repo, prefix, preReleaseVersion)
+	if err != nil {
+		return EmptyRef, err
+	}
 	return SemverRef{
 		Version: &preReleaseVersion,
 		Ref:     head,
 	}, nil
 }
 
+// markerSnapshot returns the pre-release of the nearest next version marker when the prefix has no release
+func markerSnapshot(repo *gogit.Repository, prefix string, head *plumbing.Reference, preRelease release.PreReleaseFunc) (SemverRef, error) {
+	marker, err := NearestMarker(repo, prefix)
+	if err != nil {
+		return EmptyRef, err
+	}
+	preReleaseVersion, err := preRelease(semver.MustParse("0.0.0"))
+	if err != nil {
+		return EmptyRef, err
+	}
+	snapshot, err := moveToMarker(marker, preReleaseVersion)
+	if err != nil {
+		return EmptyRef, err
+	}
+	return SemverRef{
+		Version: &snapshot,
+		Ref:     head,
+	}, nil
+}
+
 func NearestTag(repo *gogit.Repository, prefix string) (SemverRef, error) {
+	return NearestTagMatching(repo, prefix, nil)
+}
+
+// NearestTagMatching returns the highest matching tag of the prefix on the closest tagged ancestor of HEAD,
+// a nil match accepts every tag.
+func NearestTagMatching(repo *gogit.Repository, prefix string, match func(version *semver.Version) bool) (SemverRef, error) {
 	head, err := repo.Head()
 	if err != nil {
 		return EmptyRef, err
 	}
 
 	// Build a map of commit hash -> matching tags (only once)
-	tagMap, err := buildTagMap(repo, prefix)
+	tagMap, err := buildTagMap(repo, prefix, match)
 	if err != nil {
 		return EmptyRef, err
 	}
 
+	if len(tagMap) == 0 {
+		return EmptyRef, ErrNoTagFound
+	}
+
 	// Check HEAD first
 	if tags, exists := tagMap[head.Hash()]; exists {
 		return tags[0], nil // Return first matching tag
@@ -386,7 +684,7 @@ func NearestTag(repo *gogit.Repository, prefix string) (SemverRef, error) {
 	return nearestTag, nil
 }
 
-func buildTagMap(repo *gogit.Repository, prefix string) (map[plumbing.Hash][]SemverRef, error) {
+func buildTagMap(repo *gogit.Repository, prefix string, match func(version *semver.Version) bool) (map[plumbing.Hash][]SemverRef, error) {
 	tagMap := make(map[plumbing.Hash][]SemverRef)
 	tagPrefix := refTagPrefix + prefix
 	re := regexp.MustCompile("^" + tagPrefix + semVerRegex + "$")
@@ -400,7 +698,7 @@ func buildTagMap(repo *gogit.Repository, prefix string) (map[plumbing.Hash][]Sem
 		tagName := ref.Name().String()
 		if re.MatchString(tagName) {
 			versionString := strings.TrimPrefix(tagName, tagPrefix)
-			if version, err := semver.NewVersion(versionString); err == nil {
+			if version, err := semver.NewVersion(versionString); err == nil && (match == nil || match(version)) {
 				// Handle both lightweight and annotated tags
 				var commitHash plumbing.Hash
 				var tagRef *plumbing.Reference
@@ -870,3 +870,72 @@ func TestNearestTagPerformance(t *testing.T) {
 	// Should complete reasonably quickly even with many tags
 	assert.Less(t, duration, 10*time.Millisecond)
 }
+
+//nolint:scopelint,paralleltest
+func TestCurrentVersionWithNextVersionMarker(t *testing.T) {
+	snapshot := func(version *semver.Version) (semver.Version, error) {
+		return version.IncMinor().SetPrerelease("SNAPSHOT")
+	}
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			assert.NoError(t, CreateMarker(r.Repo, "1.0.0", prefix, false))
+			{
+				cr, err := CurrentVersion(r.Repo, prefix, snapshot, GetOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, "1.0.0-SNAPSHOT", cr.Version.String())
+				distance := release.PreRelease(r.Repo, release.PreReleaseOptions{TagPrefix: prefix, Strategy: release.DistanceStrategy})
+				cr, err = CurrentVersion(r.Repo, prefix, distance, GetOptions{})
+				assert.NoError(t, err)
+				assert.Regexp(t, `^1\.0\.0-SNAPSHOT\.\d+$`, cr.Version.String())
+			}
+
+			r.DoCommit("foo")
+			assert.NoError(t, CreateTag(r.Repo, "0.4.0", prefix, false))
+			r.DoCommit("bar")
+			{
+				cr, err := CurrentVersion(r.Repo, prefix, snapshot, GetOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, "1.0.0-SNAPSHOT", cr.Version.String())
+			}
+
+			assert.NoError(t, CreateTag(r.Repo, "1.0.0", prefix, false))
+			r.DoCommit("baz")
+			{
+				cr, err := CurrentVersion(r.Repo, prefix, snapshot, GetOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, "1.1.0-SNAPSHOT", cr.Version.String())
+			}
+			refs, err := ListRefs(r.Repo, prefix, DESC, 10)
+			assert.NoError(t, err)
+			assert.Len(t, refs, 2)
+		})
+	}
+}
+
+func TestTagPrefixes(t *testing.T) {
+	r := NewTestRepo(t)
+	for _, tag := range []string{"v1.0.0", "app-1.2.0", "app-1.3.0-rc.1", "my-app-0.1.0", "orange/v2.0.0", "not-a-version", "1.0.0", "vergo-next/app-2.0.0"} {
+		r.CreateTag(tag, r.Head().Hash())
+	}
+	prefixes, err := TagPrefixes(r.Repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app-", "my-app-", "orange/v", "v"}, prefixes)
+}
+
+func TestCreateTags(t *testing.T) {
+	r := NewTestRepo(t)
+	assert.NoError(t, CreateTags(r.Repo, "1.0.0", []string{"sdk-go-", "sdk-java-"}, TagOptions{}, false))
+	for _, tag := range []string{"sdk-go-1.0.0", "sdk-java-1.0.0"} {
+		found, err := TagExists(r.Repo, tag)
+		assert.NoError(t, err)
+		assert.True(t, found, tag)
+	}
+
+	r.CreateTag("sdk-java-1.1.0", r.Head().Hash())
+	err := CreateTags(r.Repo, "1.1.0", []string{"sdk-go-", "sdk-java-"}, TagOptions{}, false)
+	assert.Regexp(t, "already exists", err)
+	found, err := TagExists(r.Repo, "sdk-go-1.1.0")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"errors"
+	"github.com/Masterminds/semver/v3"
+	gogit "github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// MarkerTagPrefix namespaces the next version marker tags e.g. vergo-next/app-1.0.0.
+const MarkerTagPrefix = "vergo-next/"
+
+// MarkerPrefix returns the tag prefix of the next version markers of the prefix.
+func MarkerPrefix(prefix string) string {
+	return MarkerTagPrefix + prefix
+}
+
+// CreateMarker tags HEAD with a next version marker.
+func CreateMarker(repo *gogit.Repository, version, prefix string, dryRun bool) error {
+	return CreateTag(repo, version, MarkerPrefix(prefix), dryRun)
+}
+
+// NearestMarker returns the next version marker nearest to HEAD in the commit history.
+func NearestMarker(repo *gogit.Repository, prefix string) (SemverRef, error) {
+	return NearestTag(repo, MarkerPrefix(prefix))
+}
+
+// WithMarker moves the version to the nearest greater next version marker, keeping its pre-release.
+func WithMarker(repo *gogit.Repository, prefix string, version semver.Version) (semver.Version, error) {
+	marker, err := NearestMarker(repo, prefix)
+	switch {
+	case errors.Is(err, ErrNoTagFound):
+		return version, nil
+	case err != nil:
+		return semver.Version{}, err
+	case !marker.Version.GreaterThan(&version):
+		return version, nil
+	}
+	return moveToMarker(marker, version)
+}
+
+func moveToMarker(marker SemverRef, version semver.Version) (semver.Version, error) {
+	log.Debugf("Next version marker %s applied to %s", marker.Version.String(), version.String())
+	moved, err := marker.Version.SetPrerelease(version.Prerelease())
+	if err != nil {
+		return semver.Version{}, err
+	}
+	return moved.SetMetadata(version.Metadata())
+}
@@ -0,0 +1,162 @@
+package git
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+const (
+	OpenPGPFormat = "openpgp"
+	SSHFormat     = "ssh"
+
+	sshSigMagic     = "SSHSIG"
+	sshSigNamespace = "git"
+	sshSigHash      = "sha512"
+	sshSigBegin     = "-----BEGIN SSH SIGNATURE-----"
+	sshSigEnd       = "-----END SSH SIGNATURE-----"
+)
+
+var (
+	ErrInvalidSigningKey    = errors.New("invalid signing key")
+	ErrInvalidSigningFormat = errors.New("invalid signing format")
+	ErrSigningKeyNotFound   = errors.New("signing key not found in the ssh agent")
+)
+
+// TagSigner signs the tag object encoded without signature, the armored signature is appended to the tag.
+type TagSigner interface {
+	Sign(payload []byte) (string, error)
+}
+
+// OpenPGPSigner signs tags with an OpenPGP key, the signatures are verified by git tag -v with gpg.
+type OpenPGPSigner struct {
+	Entity *openpgp.Entity
+}
+
+// NewOpenPGPSigner reads the first key of the armored private key, encrypted keys are decrypted with the passphrase.
+func NewOpenPGPSigner(armoredKey io.Reader, passphrase []byte) (*OpenPGPSigner, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(armoredKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidSigningKey, err)
+	}
+	if len(entities) == 0 || entities[0].PrivateKey == nil {
+		return nil, fmt.Errorf("%w : no private key", ErrInvalidSigningKey)
+	}
+	entity := entities[0]
+	if entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt(passphrase); err != nil {
+			return nil, fmt.Errorf("%w : %s", ErrInvalidSigningKey, err)
+		}
+	}
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			if err := subkey.PrivateKey.Decrypt(passphrase); err != nil {
+				return nil, fmt.Errorf("%w : %s", ErrInvalidSigningKey, err)
+			}
+		}
+	}
+	return &OpenPGPSigner{Entity: entity}, nil
+}
+
+func (s *OpenPGPSigner) Sign(payload []byte) (string, error) {
+	var signature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&signature, s.Entity, bytes.NewReader(payload), nil); err != nil {
+		return "", err
+	}
+	return signature.String() + "\n", nil
+}
+
+// SSHSigner signs tags with the sshsig protocol, using the ssh agent key of PublicKey without Signer.
+type SSHSigner struct {
+	PublicKey ssh.PublicKey
+	Signer    ssh.Signer
+}
+
+// NewSSHAgentSigner returns the signer of the agent key matching the authorized_keys formatted public key.
+func NewSSHAgentSigner(publicKey []byte) (*SSHSigner, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidSigningKey, err)
+	}
+	return &SSHSigner{PublicKey: key}, nil
+}
+
+func (s *SSHSigner) Sign(payload []byte) (string, error) {
+	if s.Signer != nil {
+		return sshSign(s.Signer, payload)
+	}
+	socket, ok := os.LookupEnv("SSH_AUTH_SOCK")
+	if !ok {
+		return "", fmt.Errorf("%w : SSH_AUTH_SOCK is not set", ErrSigningKeyNotFound)
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		return "", err
+	}
+	for _, signer := range signers {
+		if bytes.Equal(signer.PublicKey().Marshal(), s.PublicKey.Marshal()) {
+			return sshSign(signer, payload)
+		}
+	}
+	return "", fmt.Errorf("%w : %s", ErrSigningKeyNotFound, ssh.FingerprintSHA256(s.PublicKey))
+}
+
+func sshSign(signer ssh.Signer, payload []byte) (string, error) {
+	hash := sha512.Sum512(payload)
+	signed := sshSigBlob(sshSigNamespace, sshSigHash, hash[:])
+	var signature *ssh.Signature
+	var err error
+	if algorithmSigner, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, signed, ssh.SigAlgoRSASHA2512)
+	} else {
+		signature, err = signer.Sign(rand.Reader, signed)
+	}
+	if err != nil {
+		return "", err
+	}
+	blob := []byte(sshSigMagic)
+	blob = append(blob, 0, 0, 0, 1)
+	blob = append(blob, ssh.Marshal(struct{ Key []byte }{signer.PublicKey().Marshal()})...)
+	blob = append(blob, ssh.Marshal(struct {
+		Namespace, Reserved, Hash string
+		Signature                 []byte
+	}{sshSigNamespace, "", sshSigHash, ssh.Marshal(signature)})...)
+	return armorSSHSignature(blob), nil
+}
+
+// sshSigBlob returns the data signed by an sshsig signature.
+func sshSigBlob(namespace, hashAlgorithm string, hash []byte) []byte {
+	return append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Namespace, Reserved, Hash string
+		Digest                    []byte
+	}{namespace, "", hashAlgorithm, hash})...)
+}
+
+func armorSSHSignature(blob []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var armored strings.Builder
+	armored.WriteString(sshSigBegin + "\n")
+	for len(encoded) > 70 {
+		armored.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	armored.WriteString(encoded + "\n" + sshSigEnd + "\n")
+	return armored.String()
+}
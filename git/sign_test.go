@@ -0,0 +1,260 @@
+package git_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+
+	. "github.com/sky-uk/vergo/git"
+	. "github.com/sky-uk/vergo/internal-test"
+)
+
+var tagger = &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()}
+
+//nolint:scopelint,paralleltest
+func TestCreateTagSignedWithOpenPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("test", "", "test@test.com", nil)
+	assert.NoError(t, err)
+	var privateKey, publicKey bytes.Buffer
+	armored, err := armor.Encode(&privateKey, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.SerializePrivate(armored, nil))
+	assert.NoError(t, armored.Close())
+	armored, err = armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.Serialize(armored))
+	assert.NoError(t, armored.Close())
+
+	signer, err := NewOpenPGPSigner(&privateKey, nil)
+	assert.NoError(t, err)
+	for _, prefix := range prefixes {
+		t.Run(prefix, func(t *testing.T) {
+			r := NewTestRepo(t)
+			options := TagOptions{Message: "release message", Tagger: tagger, Signer: signer}
+			assert.NoError(t, CreateTagWithOptions(r.Repo, "1.0.0", prefix, options, false))
+
+			ref, err := r.Repo.Tag(prefix + "1.0.0")
+			assert.NoError(t, err)
+			tag, err := r.Repo.TagObject(ref.Hash())
+			assert.NoError(t, err)
+			assert.Equal(t, "release message\n", tag.Message)
+			_, err = tag.Verify(publicKey.String())
+			assert.NoError(t, err)
+
+			keyring, err := ReadOpenPGPKeyring(strings.NewReader(publicKey.String()))
+			assert.NoError(t, err)
+			signature, err := VerifyTag(r.Repo, prefix+"1.0.0", TrustedKeys{OpenPGP: keyring})
+			assert.NoError(t, err)
+			assert.Equal(t, &TagSignature{
+				Tag:         prefix + "1.0.0",
+				Format:      OpenPGPFormat,
+				Signer:      "test <test@test.com>",
+				Fingerprint: fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint),
+			}, signature)
+			_, err = VerifyTag(r.Repo, prefix+"1.0.0", TrustedKeys{})
+			assert.ErrorIs(t, err, ErrUntrustedSignature)
+		})
+	}
+}
+
+func TestNewOpenPGPSignerShouldRejectInvalidKey(t *testing.T) {
+	_, err := NewOpenPGPSigner(strings.NewReader("not a key"), nil)
+	assert.ErrorIs(t, err, ErrInvalidSigningKey)
+}
+
+//nolint:paralleltest
+func TestCreateTagSignedWithSSH(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	sshSigner, err := ssh.NewSignerFromKey(key)
+	assert.NoError(t, err)
+	signer := &SSHSigner{PublicKey: sshSigner.PublicKey(), Signer: sshSigner}
+
+	r := NewTestRepo(t)
+	options := TagOptions{Message: "release message", Tagger: tagger, Signer: signer}
+	assert.NoError(t, CreateTagWithOptions(r.Repo, "1.0.0", "app-", options, false))
+	ref, err := r.Repo.Tag("app-1.0.0")
+	assert.NoError(t, err)
+	tag, err := r.Repo.TagObject(ref.Hash())
+	assert.NoError(t, err)
+	assert.Regexp(t, "(?s)^release message\n-----BEGIN SSH SIGNATURE-----\n.*-----END SSH SIGNATURE-----\n$", tag.Message)
+
+	authorizedKey := string(ssh.MarshalAuthorizedKey(sshSigner.PublicKey()))
+	for _, testCase := range []struct {
+		allowedSigners string
+		err            error
+	}{
+		{allowedSigners: "release@test.com,ci@test.com " + authorizedKey},
+		{allowedSigners: "# comment\n\nrelease@test.com,ci@test.com namespaces=\"file,git\" " + authorizedKey},
+		{allowedSigners: "release@test.com namespaces=\"file\" " + authorizedKey, err: ErrUntrustedSignature},
+		{allowedSigners: "release@test.com cert-authority " + authorizedKey, err: ErrUntrustedSignature},
+	} {
+		allowed, err := ReadAllowedSigners(strings.NewReader(testCase.allowedSigners))
+		assert.NoError(t, err)
+		signature, err := VerifyTag(r.Repo, "app-1.0.0", TrustedKeys{AllowedSigners: allowed})
+		if testCase.err != nil {
+			assert.ErrorIs(t, err, testCase.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, &TagSignature{
+			Tag:         "app-1.0.0",
+			Format:      SSHFormat,
+			Signer:      "release@test.com,ci@test.com",
+			Fingerprint: ssh.FingerprintSHA256(sshSigner.PublicKey()),
+		}, signature)
+	}
+
+	replayed := plumbing.NewHashReference(plumbing.NewTagReferenceName("app-2.0.0"), ref.Hash())
+	assert.NoError(t, r.Repo.Storer.SetReference(replayed))
+	allowed, err := ReadAllowedSigners(strings.NewReader("release@test.com " + authorizedKey))
+	assert.NoError(t, err)
+	_, err = VerifyTag(r.Repo, "app-2.0.0", TrustedKeys{AllowedSigners: allowed})
+	assert.ErrorIs(t, err, ErrTagNameMismatch)
+
+	payload := []byte("payload")
+	armored, err := signer.Sign(payload)
+	assert.NoError(t, err)
+	assert.NoError(t, verifySSHSignature(sshSigner.PublicKey(), payload, armored))
+	assert.Error(t, verifySSHSignature(sshSigner.PublicKey(), []byte("tampered"), armored))
+}
+
+func TestNewSSHAgentSignerShouldRejectInvalidKey(t *testing.T) {
+	_, err := NewSSHAgentSigner([]byte("not a key"))
+	assert.ErrorIs(t, err, ErrInvalidSigningKey)
+}
+
+func TestVerifyTagShouldRejectUnsignedTags(t *testing.T) {
+	r := NewTestRepo(t)
+	assert.NoError(t, CreateTag(r.Repo, "1.0.0", "app-", false))
+	_, err := VerifyTag(r.Repo, "app-1.0.0", TrustedKeys{})
+	assert.ErrorIs(t, err, ErrUnsignedTag)
+
+	assert.NoError(t, CreateTagWithMessage(r.Repo, "1.1.0", "app-", "release message", tagger, false))
+	_, err = VerifyTag(r.Repo, "app-1.1.0", TrustedKeys{})
+	assert.ErrorIs(t, err, ErrUnsignedTag)
+}
+
+// verifySSHSignature checks an armored sshsig signature of the git namespace as ssh-keygen -Y verify does.
+func verifySSHSignature(publicKey ssh.PublicKey, payload []byte, armored string) error {
+	encoded := strings.TrimSpace(armored)
+	encoded = strings.TrimPrefix(encoded, "-----BEGIN SSH SIGNATURE-----")
+	encoded = strings.TrimSuffix(encoded, "-----END SSH SIGNATURE-----")
+	blob, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(encoded, "\n", ""))
+	if err != nil {
+		return err
+	}
+	var sig struct {
+		Version                   uint32
+		PublicKey                 []byte
+		Namespace, Reserved, Hash string
+		Signature                 []byte
+	}
+	if err := ssh.Unmarshal(bytes.TrimPrefix(blob, []byte("SSHSIG")), &sig); err != nil {
+		return err
+	}
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &signature); err != nil {
+		return err
+	}
+	hash := sha512.Sum512(payload)
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace, Reserved, Hash string
+		Digest                    []byte
+	}{"git", "", "sha512", hash[:]})...)
+	return publicKey.Verify(signed, &signature)
+}
+
+// gitTagVerify runs git tag -v on the tag, skipping the test without git.
+func gitTagVerify(t *testing.T, dir, tag string, env []string, config ...string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	args := []string{"-C", dir}
+	for _, c := range config {
+		args = append(args, "-c", c)
+	}
+	cmd := exec.Command("git", append(args, "tag", "-v", tag)...)
+	cmd.Env = append(os.Environ(), append(env, "GIT_CONFIG_NOSYSTEM=1", "GIT_CONFIG_GLOBAL="+os.DevNull)...)
+	output, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(output))
+}
+
+//nolint:paralleltest
+func TestGitShouldVerifyOpenPGPSignedTags(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+	entity, err := openpgp.NewEntity("test", "", "test@test.com", nil)
+	assert.NoError(t, err)
+	var privateKey, publicKey bytes.Buffer
+	armored, err := armor.Encode(&privateKey, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.SerializePrivate(armored, nil))
+	assert.NoError(t, armored.Close())
+	armored, err = armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.Serialize(armored))
+	assert.NoError(t, armored.Close())
+	signer, err := NewOpenPGPSigner(&privateKey, nil)
+	assert.NoError(t, err)
+
+	gnupgHome := t.TempDir()
+	env := []string{"GNUPGHOME=" + gnupgHome}
+	importKey := exec.Command("gpg", "--batch", "--import")
+	importKey.Env = append(os.Environ(), env...)
+	importKey.Stdin = &publicKey
+	output, err := importKey.CombinedOutput()
+	assert.NoError(t, err, string(output))
+
+	repo, dir := PersistentRepository(t)
+	DoCommit(t, repo, "foo")
+	for version, message := range map[string]string{"1.0.0": "release message", "2.0.0": ""} {
+		options := TagOptions{Message: message, Tagger: tagger, Signer: signer}
+		assert.NoError(t, CreateTagWithOptions(repo, version, "app-", options, false))
+		gitTagVerify(t, dir, "app-"+version, env)
+	}
+	ref, err := repo.Tag("app-2.0.0")
+	assert.NoError(t, err)
+	tag, err := repo.TagObject(ref.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, "Release app-2.0.0\n", tag.Message, "signed tags are annotated without message")
+}
+
+//nolint:paralleltest
+func TestGitShouldVerifySSHSignedTags(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	sshSigner, err := ssh.NewSignerFromKey(key)
+	assert.NoError(t, err)
+	signer := &SSHSigner{PublicKey: sshSigner.PublicKey(), Signer: sshSigner}
+	allowedSigners := filepath.Join(t.TempDir(), "allowed_signers")
+	assert.NoError(t, os.WriteFile(allowedSigners,
+		[]byte("test@test.com "+string(ssh.MarshalAuthorizedKey(sshSigner.PublicKey()))), 0600))
+
+	repo, dir := PersistentRepository(t)
+	DoCommit(t, repo, "foo")
+	options := TagOptions{Message: "release message", Tagger: tagger, Signer: signer}
+	assert.NoError(t, CreateTagWithOptions(repo, "1.0.0", "app-", options, false))
+	gitTagVerify(t, dir, "app-1.0.0", nil, "gpg.ssh.allowedSignersFile="+allowedSigners)
+}
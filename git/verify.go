@@ -0,0 +1,223 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"strings"
+)
+
+var (
+	ErrUnsignedTag        = errors.New("tag is not signed")
+	ErrUntrustedSignature = errors.New("tag signature is not trusted")
+	ErrInvalidSignature   = errors.New("invalid tag signature")
+	ErrTagNameMismatch    = errors.New("tag object has another name")
+)
+
+// TrustedKeys are the keys accepted by VerifyTag.
+type TrustedKeys struct {
+	OpenPGP        openpgp.EntityList
+	AllowedSigners []AllowedSigner
+}
+
+// AllowedSigner is an entry of an ssh allowed signers file, any namespace when Namespaces is empty.
+type AllowedSigner struct {
+	Principals []string
+	Namespaces []string
+	PublicKey  ssh.PublicKey
+}
+
+// TagSignature is the verified signature of a tag, Signer is the OpenPGP user id or the ssh principals.
+type TagSignature struct {
+	Tag         string
+	Format      string
+	Signer      string
+	Fingerprint string
+}
+
+// ReadOpenPGPKeyring reads armored or binary OpenPGP public keys.
+func ReadOpenPGPKeyring(r io.Reader) (openpgp.EntityList, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+	if err != nil {
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(content))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidSigningKey, err)
+	}
+	return entities, nil
+}
+
+// ReadAllowedSigners reads an ssh allowed signers file, cert-authority keys are skipped.
+func ReadAllowedSigners(r io.Reader) ([]AllowedSigner, error) {
+	var signers []AllowedSigner
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%w : %s", ErrInvalidSigningKey, line)
+		}
+		key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(strings.Join(fields[1:], " ")))
+		if err != nil {
+			return nil, fmt.Errorf("%w : %s", ErrInvalidSigningKey, err)
+		}
+		signer := AllowedSigner{Principals: strings.Split(strings.Trim(fields[0], `"`), ","), PublicKey: key}
+		certAuthority := false
+		for _, option := range options {
+			switch {
+			case strings.EqualFold(option, "cert-authority"):
+				certAuthority = true
+			case strings.HasPrefix(strings.ToLower(option), "namespaces="):
+				signer.Namespaces = strings.Split(strings.Trim(option[len("namespaces="):], `"`), ",")
+			}
+		}
+		if certAuthority {
+			log.Warnf("Skipped cert-authority key of %s, certificates are not supported", fields[0])
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	return signers, scanner.Err()
+}
+
+// VerifyTag verifies the signature of the annotated tag against the trusted keys.
+func VerifyTag(repo *gogit.Repository, name string, trusted TrustedKeys) (*TagSignature, error) {
+	ref, err := repo.Tag(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", err, name)
+	}
+	tag, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w : %s is a lightweight tag", ErrUnsignedTag, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tag.Name != name {
+		return nil, fmt.Errorf("%w : %s points to %s", ErrTagNameMismatch, name, tag.Name)
+	}
+	// go-git only separates OpenPGP signatures from the message
+	signature := tag.PGPSignature
+	if signature == "" {
+		if i := strings.Index(tag.Message, sshSigBegin); i >= 0 {
+			tag.Message, signature = tag.Message[:i], tag.Message[i:]
+		}
+	}
+	if signature == "" {
+		return nil, fmt.Errorf("%w : %s", ErrUnsignedTag, name)
+	}
+	unsigned := &plumbing.MemoryObject{}
+	if err := tag.EncodeWithoutSignature(unsigned); err != nil {
+		return nil, err
+	}
+	reader, err := unsigned.Reader()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var verified *TagSignature
+	if strings.HasPrefix(signature, sshSigBegin) {
+		verified, err = verifySSH(payload, signature, trusted.AllowedSigners)
+	} else {
+		verified, err = verifyOpenPGP(payload, signature, trusted.OpenPGP)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", err, name)
+	}
+	verified.Tag = name
+	return verified, nil
+}
+
+func verifyOpenPGP(payload []byte, signature string, keyring openpgp.EntityList) (*TagSignature, error) {
+	entity, err := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(payload), strings.NewReader(signature), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrUntrustedSignature, err)
+	}
+	verified := &TagSignature{Format: OpenPGPFormat, Fingerprint: fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)}
+	if identity := entity.PrimaryIdentity(); identity != nil {
+		verified.Signer = identity.Name
+	}
+	return verified, nil
+}
+
+func verifySSH(payload []byte, armored string, allowed []AllowedSigner) (*TagSignature, error) {
+	encoded := strings.TrimSpace(armored)
+	encoded = strings.TrimSuffix(strings.TrimPrefix(encoded, sshSigBegin), sshSigEnd)
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil || !bytes.HasPrefix(blob, []byte(sshSigMagic)) {
+		return nil, fmt.Errorf("%w : malformed ssh signature", ErrInvalidSignature)
+	}
+	var sig struct {
+		Version                   uint32
+		PublicKey                 []byte
+		Namespace, Reserved, Hash string
+		Signature                 []byte
+	}
+	if err := ssh.Unmarshal(blob[len(sshSigMagic):], &sig); err != nil || sig.Version != 1 {
+		return nil, fmt.Errorf("%w : malformed ssh signature", ErrInvalidSignature)
+	}
+	if sig.Namespace != sshSigNamespace {
+		return nil, fmt.Errorf("%w : namespace %s", ErrInvalidSignature, sig.Namespace)
+	}
+	var digest []byte
+	switch sig.Hash {
+	case "sha512":
+		hash := sha512.Sum512(payload)
+		digest = hash[:]
+	case "sha256":
+		hash := sha256.Sum256(payload)
+		digest = hash[:]
+	default:
+		return nil, fmt.Errorf("%w : hash %s", ErrInvalidSignature, sig.Hash)
+	}
+	key, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidSignature, err)
+	}
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &signature); err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidSignature, err)
+	}
+	if err := key.Verify(sshSigBlob(sig.Namespace, sig.Hash, digest), &signature); err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidSignature, err)
+	}
+	fingerprint := ssh.FingerprintSHA256(key)
+	for _, signer := range allowed {
+		if bytes.Equal(signer.PublicKey.Marshal(), key.Marshal()) && signer.allows(sig.Namespace) {
+			return &TagSignature{Format: SSHFormat, Signer: strings.Join(signer.Principals, ","), Fingerprint: fingerprint}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w : ssh key %s", ErrUntrustedSignature, fingerprint)
+}
+
+func (s AllowedSigner) allows(namespace string) bool {
+	if len(s.Namespaces) == 0 {
+		return true
+	}
+	for _, allowed := range s.Namespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const regexBranchPrefix = "re:"
+
+var (
+	ErrInvalidBranchPattern = errors.New("invalid branch pattern")
+)
+
+// BranchPattern matches a branch name exactly, as a glob e.g. release/* or as a regular expression
+// when prefixed with re: e.g. re:hotfix-.* which is anchored at both ends.
+type BranchPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func ParseBranchPattern(pattern string) (BranchPattern, error) {
+	if strings.HasPrefix(pattern, regexBranchPrefix) {
+		re, err := regexp.Compile("^(?:" + strings.TrimPrefix(pattern, regexBranchPrefix) + ")$")
+		if err != nil {
+			return BranchPattern{}, fmt.Errorf("%w : %s", ErrInvalidBranchPattern, pattern)
+		}
+		return BranchPattern{pattern: pattern, re: re}, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return BranchPattern{}, fmt.Errorf("%w : %s", ErrInvalidBranchPattern, pattern)
+	}
+	return BranchPattern{pattern: pattern}, nil
+}
+
+// Literal returns true when the pattern only matches the branch with the same name.
+func (p BranchPattern) Literal() bool {
+	return p.re == nil && !strings.ContainsAny(p.pattern, `*?[\`)
+}
+
+func (p BranchPattern) Match(branch string) bool {
+	if p.re != nil {
+		return p.re.MatchString(branch)
+	}
+	matched, _ := path.Match(p.pattern, branch)
+	return matched
+}
+
+// TrimPrefix returns the branch without the literal prefix of the pattern e.g. login-flow for feature/login-flow
+// matching feature/*, the branch is returned as it is when the prefix is the whole branch name.
+func (p BranchPattern) TrimPrefix(branch string) string {
+	prefix := p.pattern
+	if p.re != nil {
+		prefix, _ = p.re.LiteralPrefix()
+	} else if i := strings.IndexAny(prefix, `*?[\`); i >= 0 {
+		prefix = prefix[:i]
+	}
+	if prefix == branch || !strings.HasPrefix(branch, prefix) {
+		return branch
+	}
+	return strings.TrimPrefix(branch, prefix)
+}
+
+func (p BranchPattern) String() string {
+	return p.pattern
+}
+
+// MatchBranch returns true when the branch matches one of the patterns.
+func MatchBranch(patterns []string, branch string) (bool, error) {
+	for _, pattern := range patterns {
+		branchPattern, err := ParseBranchPattern(pattern)
+		if err != nil {
+			return false, err
+		}
+		if branchPattern.Match(branch) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchingBranchRefs returns the local branches and the branches of the remote matching the pattern, one per branch
+// name, the branch of the remote when both exist.
+func matchingBranchRefs(repo *gogit.Repository, pattern BranchPattern, remoteName string) ([]plumbing.ReferenceName, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	remotePrefix := remoteName + "/"
+	byName := make(map[string]plumbing.ReferenceName)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		switch name := ref.Name(); {
+		case name.IsRemote() && strings.HasPrefix(name.Short(), remotePrefix):
+			if branch := strings.TrimPrefix(name.Short(), remotePrefix); branch != "HEAD" && pattern.Match(branch) {
+				byName[branch] = name
+			}
+		case name.IsBranch():
+			if _, found := byName[name.Short()]; !found && pattern.Match(name.Short()) {
+				byName[name.Short()] = name
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	branchRefs := make([]plumbing.ReferenceName, 0, len(byName))
+	for _, name := range byName {
+		branchRefs = append(branchRefs, name)
+	}
+	sort.Slice(branchRefs, func(i, j int) bool {
+		return branchRefs[i] < branchRefs[j]
+	})
+	return branchRefs, nil
+}
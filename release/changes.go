@@ -0,0 +1,153 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	log "github.com/sirupsen/logrus"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNoChanges       = errors.New("no changes since the latest release")
+	ErrInvalidPathGlob = errors.New("invalid path glob")
+)
+
+// PathFilter selects the files of a project, Paths and IgnorePaths are globs relative to the repository root where
+// * and ? do not match / while ** matches any number of directories, a glob also matches everything below it
+// e.g. services/api matches services/api/main.go. Empty Paths select every file.
+type PathFilter struct {
+	Paths       []string
+	IgnorePaths []string
+}
+
+// Match returns true when the file is selected by Paths and not by IgnorePaths.
+func (f PathFilter) Match(file string) (bool, error) {
+	ignored, err := matchGlobs(f.IgnorePaths, file)
+	if err != nil || ignored {
+		return false, err
+	}
+	if len(f.Paths) == 0 {
+		return true, nil
+	}
+	return matchGlobs(f.Paths, file)
+}
+
+func matchGlobs(globs []string, file string) (bool, error) {
+	for _, glob := range globs {
+		re, err := globRegex(glob)
+		if err != nil {
+			return false, err
+		}
+		if re.MatchString(file) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func globRegex(glob string) (*regexp.Regexp, error) {
+	glob = strings.Trim(strings.TrimPrefix(strings.TrimSpace(glob), "./"), "/")
+	var expression strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			if i+1 < len(glob) && glob[i+1] == '/' {
+				i++
+				expression.WriteString("(?:.*/)?")
+			} else {
+				expression.WriteString(".*")
+			}
+		case c == '*':
+			expression.WriteString("[^/]*")
+		case c == '?':
+			expression.WriteString("[^/]")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re, err := regexp.Compile("^" + expression.String() + "(?:/.*)?$")
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidPathGlob, glob)
+	}
+	return re, nil
+}
+
+// ChangedFiles returns the sorted files added, modified or deleted between since and HEAD,
+// since is the commit or tag object of the latest release.
+func ChangedFiles(repo *gogit.Repository, since plumbing.Hash) ([]string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	sinceCommit, err := peelToCommit(repo, since)
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	sinceTree, err := sinceCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(sinceTree, headTree)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name != "" {
+				names[name] = true
+			}
+		}
+	}
+	files := make([]string, 0, len(names))
+	for name := range names {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+type ChangedFunc func(repo *gogit.Repository, since plumbing.Hash, filter PathFilter) ([]string, error)
+
+// Changed returns the files selected by the filter which changed between since and HEAD, ErrNoChanges when none did.
+// Without a release, plumbing.ZeroHash, the project is always changed.
+func Changed(repo *gogit.Repository, since plumbing.Hash, filter PathFilter) ([]string, error) {
+	if since.IsZero() {
+		log.Debug("No release yet, treating the project as changed")
+		return nil, nil
+	}
+	files, err := ChangedFiles(repo, since)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, file := range files {
+		match, err := filter.Match(file)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			changed = append(changed, file)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, fmt.Errorf("%w : paths %s ignoring %s", ErrNoChanges,
+			strings.Join(filter.Paths, ", "), strings.Join(filter.IgnorePaths, ", "))
+	}
+	log.Debugf("Changed files: %s", strings.Join(changed, ", "))
+	return changed, nil
+}
@@ -0,0 +1,114 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"sort"
+	"strings"
+)
+
+const branchPlaceholder = "{branch}"
+
+var (
+	ErrInvalidChannel = errors.New("invalid pre-release channel")
+)
+
+// Channel maps the branches matching Pattern to the pre-release Identifier, {branch} in the identifier is replaced
+// with the branch name without the literal prefix of the pattern e.g. feature/*={branch} gives login-flow
+// on feature/login-flow.
+type Channel struct {
+	Pattern    BranchPattern
+	Identifier string
+}
+
+// ParseChannels parses channels in the form pattern=identifier e.g. develop=beta.
+func ParseChannels(channels []string) ([]Channel, error) {
+	parsed := make([]Channel, 0, len(channels))
+	for _, channel := range channels {
+		parts := strings.SplitN(channel, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("%w : %s must be pattern=identifier", ErrInvalidChannel, channel)
+		}
+		branchPattern, err := ParseBranchPattern(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, Channel{Pattern: branchPattern, Identifier: strings.TrimSpace(parts[1])})
+	}
+	return parsed, nil
+}
+
+// IdentifierFor returns the sanitised pre-release identifier of the branch.
+func (c Channel) IdentifierFor(branch string) (string, error) {
+	identifier := SanitiseIdentifier(strings.ReplaceAll(c.Identifier, branchPlaceholder, c.Pattern.TrimPrefix(branch)))
+	if identifier == "" {
+		return "", fmt.Errorf("%w : %s gives an empty identifier for branch %s", ErrInvalidChannel, c.Identifier, branch)
+	}
+	return identifier, nil
+}
+
+type CurrentChannelFunc func(repo *gogit.Repository, remoteName string, channels []string) (string, error)
+
+// CurrentChannel returns the pre-release identifier of the first channel matching the checked out branch,
+// empty when no channel matches. On a headless checkout the local and remote branches pointing at HEAD are matched.
+func CurrentChannel(repo *gogit.Repository, remoteName string, channels []string) (string, error) {
+	if len(channels) == 0 {
+		return "", nil
+	}
+	parsed, err := ParseChannels(channels)
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	branches := []string{head.Name().Short()}
+	if head.Name() == plumbing.HEAD {
+		if branches, err = branchesAt(repo, remoteName, head.Hash()); err != nil {
+			return "", err
+		}
+	}
+	for _, channel := range parsed {
+		for _, branch := range branches {
+			if channel.Pattern.Match(branch) {
+				return channel.IdentifierFor(branch)
+			}
+		}
+	}
+	return "", nil
+}
+
+// branchesAt returns the sorted names of the local branches and branches of the remote pointing at the commit,
+// remote branches are returned without the remote name.
+func branchesAt(repo *gogit.Repository, remoteName string, commit plumbing.Hash) ([]string, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	remotePrefix := remoteName + "/"
+	names := make(map[string]bool)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || ref.Hash() != commit {
+			return nil
+		}
+		switch {
+		case ref.Name().IsBranch():
+			names[ref.Name().Short()] = true
+		case ref.Name().IsRemote() && strings.HasPrefix(ref.Name().Short(), remotePrefix):
+			names[strings.TrimPrefix(ref.Name().Short(), remotePrefix)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	branches := make([]string, 0, len(names))
+	for name := range names {
+		branches = append(branches, name)
+	}
+	sort.Strings(branches)
+	return branches, nil
+}
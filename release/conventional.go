@@ -0,0 +1,151 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	log "github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+)
+
+var (
+	conventionalHeader   = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()\r\n]*)\))?(!)?: (.+)$`)
+	conventionalBreaking = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+)
+
+// ConventionalCommit is the parsed header of a commit following https://www.conventionalcommits.org
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+}
+
+// ParseConventionalCommit parses the commit message, returns false when the message is not a conventional commit.
+func ParseConventionalCommit(message string) (ConventionalCommit, bool) {
+	header := strings.TrimSpace(strings.SplitN(strings.TrimSpace(message), "\n", 2)[0])
+	match := conventionalHeader.FindStringSubmatch(header)
+	if match == nil {
+		return ConventionalCommit{}, false
+	}
+	return ConventionalCommit{
+		Type:        strings.ToLower(match[1]),
+		Scope:       strings.TrimSpace(match[2]),
+		Description: strings.TrimSpace(match[4]),
+		Breaking:    match[3] == "!" || conventionalBreaking.MatchString(message),
+	}, true
+}
+
+// Increment returns the increment implied by the commit, empty if the commit does not require a release.
+func (c ConventionalCommit) Increment() string {
+	switch {
+	case c.Breaking:
+		return "major"
+	case c.Type == "feat":
+		return "minor"
+	case c.Type == "fix", c.Type == "perf":
+		return "patch"
+	default:
+		return ""
+	}
+}
+
+var incrementRank = map[string]int{"": 0, "patch": 1, "minor": 2, "major": 3}
+
+type ConventionalOptions struct {
+	// ScopeAsPrefix only counts scoped commits when the scope matches the tag prefix, unscoped commits count for every prefix
+	ScopeAsPrefix bool
+}
+
+type ConventionalIncrementFunc func(repo *gogit.Repository, tagPrefixRaw string, since plumbing.Hash,
+	options ConventionalOptions) (string, error)
+
+// ConventionalIncrement returns the highest increment of the conventional commits between since and HEAD.
+// since is the commit or tag object of the latest release, plumbing.ZeroHash when there is no release yet,
+// in which case the increment is minor without conventional commits.
+func ConventionalIncrement(repo *gogit.Repository, tagPrefixRaw string, since plumbing.Hash,
+	options ConventionalOptions) (string, error) {
+	commits, err := CommitsSince(repo, since)
+	if err != nil {
+		return "", err
+	}
+	increment := ""
+	for _, commit := range commits {
+		conventionalCommit, ok := ParseConventionalCommit(commit.Message)
+		if !ok {
+			continue
+		}
+		if options.ScopeAsPrefix && conventionalCommit.Scope != "" && conventionalCommit.Scope != tagPrefixRaw {
+			log.Debugf("Commit %s scope %s does not match prefix %s", commit.Hash, conventionalCommit.Scope, tagPrefixRaw)
+			continue
+		}
+		if commitIncrement := conventionalCommit.Increment(); incrementRank[commitIncrement] > incrementRank[increment] {
+			increment = commitIncrement
+		}
+	}
+	switch {
+	case increment != "":
+		return increment, nil
+	case since.IsZero():
+		return "minor", nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrNoIncrement, tagPrefixRaw)
+	}
+}
+
+// CommitsSince returns the commits reachable from HEAD but not from since.
+// since is a commit or tag object, plumbing.ZeroHash returns the whole history.
+func CommitsSince(repo *gogit.Repository, since plumbing.Hash) ([]*object.Commit, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	return CommitsBetween(repo, since, head.Hash())
+}
+
+// CommitsBetween returns the commits reachable from until but not from since, like git log since..until,
+// both may be commit or tag objects.
+func CommitsBetween(repo *gogit.Repository, since, until plumbing.Hash) ([]*object.Commit, error) {
+	untilCommit, err := peelToCommit(repo, until)
+	if err != nil {
+		return nil, err
+	}
+	excluded := make(map[plumbing.Hash]bool)
+	if !since.IsZero() {
+		sinceCommit, err := peelToCommit(repo, since)
+		if err != nil {
+			return nil, err
+		}
+		err = object.NewCommitPreorderIter(sinceCommit, nil, nil).ForEach(func(commit *object.Commit) error {
+			excluded[commit.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var commits []*object.Commit
+	err = object.NewCommitPreorderIter(untilCommit, excluded, nil).ForEach(func(commit *object.Commit) error {
+		commits = append(commits, commit)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+func peelToCommit(repo *gogit.Repository, hash plumbing.Hash) (*object.Commit, error) {
+	tagObject, err := repo.TagObject(hash)
+	switch {
+	case err == nil:
+		return tagObject.Commit()
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		return repo.CommitObject(hash)
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,181 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	log "github.com/sirupsen/logrus"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	majorPlaceholder = "{major}"
+	minorPlaceholder = "{minor}"
+)
+
+var (
+	ErrInvalidVersionLinePattern = errors.New("invalid version line pattern")
+	ErrOutsideVersionLine        = errors.New("version is outside the version line")
+	// DefaultVersionLinePatterns are empty, version lines are opt-in e.g. release/{major}.{minor} or support/{major}.x
+	DefaultVersionLinePatterns []string
+)
+
+// VersionLine is the range of versions a maintenance branch releases, release/1.4 only releases 1.4.x
+// while support/1.x releases 1.x.y.
+type VersionLine struct {
+	Branch string
+	Major  uint64
+	Minor  uint64
+	// PatchOnly is set when the branch fixes the minor version
+	PatchOnly bool
+}
+
+// Contains returns true when the version belongs to the line.
+func (l VersionLine) Contains(version *semver.Version) bool {
+	return version.Major() == l.Major && (!l.PatchOnly || version.Minor() == l.Minor)
+}
+
+// Initial returns the first release of the line, 1.4.0 for release/1.4 and 1.0.0 for support/1.x.
+func (l VersionLine) Initial() *semver.Version {
+	return semver.MustParse(fmt.Sprintf("%d.%d.0", l.Major, l.Minor))
+}
+
+func (l VersionLine) String() string {
+	if l.PatchOnly {
+		return fmt.Sprintf("%d.%d.x", l.Major, l.Minor)
+	}
+	return fmt.Sprintf("%d.x", l.Major)
+}
+
+func versionLineRegex(pattern string) (*regexp.Regexp, error) {
+	if !strings.Contains(pattern, majorPlaceholder) {
+		return nil, fmt.Errorf("%w : %s must contain %s", ErrInvalidVersionLinePattern, pattern, majorPlaceholder)
+	}
+	expression := regexp.QuoteMeta(pattern)
+	expression = strings.Replace(expression, regexp.QuoteMeta(majorPlaceholder), `(?P<major>\d+)`, 1)
+	expression = strings.Replace(expression, regexp.QuoteMeta(minorPlaceholder), `(?P<minor>\d+)`, 1)
+	return regexp.Compile("^" + expression + "$")
+}
+
+// ParseVersionLine returns the version line of the branch when it matches the pattern e.g. release/{major}.{minor}.
+func ParseVersionLine(pattern, branch string) (*VersionLine, error) {
+	re, err := versionLineRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	match := re.FindStringSubmatch(branch)
+	if match == nil {
+		return nil, nil
+	}
+	line := VersionLine{Branch: branch}
+	for i, name := range re.SubexpNames() {
+		switch name {
+		case "major":
+			line.Major, err = strconv.ParseUint(match[i], 10, 64)
+		case "minor":
+			line.Minor, err = strconv.ParseUint(match[i], 10, 64)
+			line.PatchOnly = true
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w : %s", ErrInvalidVersionLinePattern, branch)
+		}
+	}
+	return &line, nil
+}
+
+func matchVersionLine(patterns []string, branch string) (*VersionLine, error) {
+	for _, pattern := range patterns {
+		line, err := ParseVersionLine(pattern, branch)
+		if err != nil || line != nil {
+			return line, err
+		}
+	}
+	return nil, nil
+}
+
+type CurrentVersionLineFunc func(repo *gogit.Repository, remoteName string, patterns []string) (*VersionLine, error)
+
+// CurrentVersionLine returns the version line of the checked out branch, nil when it is not a maintenance branch.
+// On a headless checkout the local and remote branches matching the patterns and containing HEAD are matched.
+func CurrentVersionLine(repo *gogit.Repository, remoteName string, patterns []string) (*VersionLine, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	for _, pattern := range patterns {
+		if _, err := versionLineRegex(pattern); err != nil {
+			return nil, err
+		}
+	}
+	head, err := repo.Head()
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	if head.Name() != plumbing.HEAD {
+		return matchVersionLine(patterns, head.Name().Short())
+	}
+	branches, err := branchesContaining(repo, remoteName, head.Hash(), func(branch string) bool {
+		line, _ := matchVersionLine(patterns, branch)
+		return line != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, branch := range branches {
+		line, err := matchVersionLine(patterns, branch)
+		if err != nil || line != nil {
+			return line, err
+		}
+	}
+	return nil, nil
+}
+
+// branchesContaining returns the sorted names of the matching local branches and branches of the remote containing
+// the commit, remote branches are returned without the remote name.
+func branchesContaining(repo *gogit.Repository, remoteName string, commit plumbing.Hash, match func(string) bool) ([]string, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	remotePrefix := remoteName + "/"
+	names := make(map[string]bool)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		var name string
+		switch {
+		case ref.Name().IsBranch():
+			name = ref.Name().Short()
+		case ref.Name().IsRemote() && strings.HasPrefix(ref.Name().Short(), remotePrefix):
+			name = strings.TrimPrefix(ref.Name().Short(), remotePrefix)
+		default:
+			return nil
+		}
+		if names[name] || name == "HEAD" || !match(name) {
+			return nil
+		}
+		onBranch, err := isCommitOnBranch(repo, commit, ref.Name())
+		if err != nil {
+			log.WithError(err).Debugf("Failed to check if commit %s is on branch %s", commit, ref.Name())
+			return nil
+		}
+		if onBranch {
+			names[name] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	branches := make([]string, 0, len(names))
+	for name := range names {
+		branches = append(branches, name)
+	}
+	sort.Strings(branches)
+	return branches, nil
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/storer"
 	log "github.com/sirupsen/logrus"
-	"github.com/thoas/go-funk"
 	"regexp"
 	"strings"
 )
@@ -46,12 +45,14 @@ func SkipHintPresent(repo *gogit.Repository, tagPrefixRaw string) error {
 	return nil
 }
 
+const incrementHints = "(major|minor|patch|premajor|preminor|prepatch|pre|promote)"
+
 func checkIncrementHint(aString, tagPrefixRaw string) (string, error) {
 	var re *regexp.Regexp
 	if tagPrefixRaw == "" {
-		re = regexp.MustCompile("vergo:(major|minor|patch|pre)-release")
+		re = regexp.MustCompile("vergo:" + incrementHints + "-release")
 	} else {
-		re = regexp.MustCompile("vergo:" + tagPrefixRaw + ":(major|minor|patch|pre)-release")
+		re = regexp.MustCompile("vergo:" + tagPrefixRaw + ":" + incrementHints + "-release")
 	}
 	match := re.FindStringSubmatch(aString)
 	if len(match) != 2 {
@@ -82,30 +83,36 @@ func IncrementHint(repo *gogit.Repository, tagPrefixRaw string) (string, error)
 
 type ValidateHEADFunc func(repo *gogit.Repository, remoteName string, versionedBranches []string) error
 
+// ValidateHEAD checks HEAD is on a versioned branch, versioned branches are names or patterns e.g. release/*
+// or re:hotfix-.* as described by BranchPattern.
 func ValidateHEAD(repo *gogit.Repository, remoteName string, versionedBranches []string) error {
 	head, err := repo.Head()
 	if err != nil {
 		return err
 	}
 	log.Debugf("Current branch:%v, short: %v", head.Name(), head.Name().Short())
+	patterns := make([]BranchPattern, 0, len(versionedBranches))
+	for _, versionedBranch := range versionedBranches {
+		pattern, err := ParseBranchPattern(versionedBranch)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, pattern)
+	}
 	isHeadlessCheckout := head.Name() == plumbing.HEAD
 	if isHeadlessCheckout {
 		validRef := false
-		for _, mainBranchName := range versionedBranches {
-			remote, err := repo.Remote(remoteName)
-			if err != nil && !errors.Is(err, gogit.ErrRemoteNotFound) {
+		for _, pattern := range patterns {
+			branchRefs, err := matchingBranchRefs(repo, pattern, remoteName)
+			if err != nil {
 				return err
 			}
-			var branchRef plumbing.ReferenceName
-			if remote != nil {
-				branchRef = plumbing.NewRemoteReferenceName(remoteName, mainBranchName)
-			} else {
-				branchRef = plumbing.NewBranchReferenceName(mainBranchName)
-			}
-			revision, err := repo.ResolveRevision(plumbing.Revision(branchRef))
-			if err != nil {
-				log.WithError(err).Debugf("branchRef could not be resolved: %s\n", branchRef.String())
-			} else {
+			for _, branchRef := range branchRefs {
+				revision, err := repo.ResolveRevision(plumbing.Revision(branchRef))
+				if err != nil {
+					log.WithError(err).Debugf("branchRef could not be resolved: %s\n", branchRef.String())
+					continue
+				}
 				commitOnVersionedBranch, err := isCommitOnBranch(repo, head.Hash(), branchRef)
 				if err != nil {
 					log.WithError(err).Errorf("Failed to check if commit %s is on branch %s\n",
@@ -115,40 +122,59 @@ func ValidateHEAD(repo *gogit.Repository, remoteName string, versionedBranches [
 				if commitOnVersionedBranch {
 					validRef = true
 					break
-				} else {
-					log.Warnf("Commit not found on branch [branch: %s, head: %s, ref: %s]\n",
-						branchRef.String(), head.Hash().String(), revision.String())
 				}
+				log.Warnf("Commit not found on branch [branch: %s, head: %s, ref: %s]\n",
+					branchRef.String(), head.Hash().String(), revision.String())
+			}
+			if validRef {
+				break
 			}
 		}
 		if !validRef {
 			return fmt.Errorf("commit %s is not on a versioned branch: %s",
 				head.Hash(), strings.Join(versionedBranches, ", "))
 		}
-	} else if !funk.ContainsString(versionedBranches, head.Name().Short()) {
+	} else if !matchAny(patterns, head.Name().Short()) {
 		return fmt.Errorf("branch %s is not in versioned branches list: %s",
 			head.Name().Short(), strings.Join(versionedBranches, ", "))
 	}
 	return nil
 }
 
+func matchAny(patterns []BranchPattern, branch string) bool {
+	for _, pattern := range patterns {
+		if pattern.Match(branch) {
+			return true
+		}
+	}
+	return false
+}
+
 type PreReleaseFunc func(version *semver.Version) (semver.Version, error)
 type PreReleaseOptions struct {
 	WithMetadata bool
+	// TagPrefix finds the tag of the latest release, the distance is counted from it
+	TagPrefix string
+	// Increment applied to the latest release: patch, minor or major, minor when empty
+	Increment string
+	// Strategy is one of SnapshotStrategies, SnapshotStrategy when empty
+	Strategy string
+	// Template renders the version with SnapshotData for the template strategy
+	Template string
 }
 
 func PreRelease(repo *gogit.Repository, options PreReleaseOptions) PreReleaseFunc {
 	return func(version *semver.Version) (semver.Version, error) {
-		pre, err := version.IncMinor().SetPrerelease("SNAPSHOT")
+		data, err := snapshotData(repo, version, options)
 		if err != nil {
 			return semver.Version{}, err
 		}
-		if options.WithMetadata {
-			head, err := repo.Head()
-			if err != nil {
-				return semver.Version{}, err
-			}
-			return pre.SetMetadata(head.Hash().String()[0:7])
+		pre, err := snapshot(data, options)
+		if err != nil {
+			return semver.Version{}, err
+		}
+		if options.WithMetadata && options.Strategy != TemplateStrategy {
+			return pre.SetMetadata(data.ShortSHA)
 		}
 		return pre, nil
 	}
@@ -3,18 +3,23 @@ package release_test
 import (
 	"fmt"
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	. "github.com/sky-uk/vergo/internal-test"
 	"github.com/sky-uk/vergo/release"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
+	"time"
 )
 
 var (
-	prefixes   = []string{"", "app", "application", "app/v"}
-	increments = []string{"patch", "minor", "major"}
-	mainBranch = []string{"master", "main"}
-	remoteName = "origin"
+	prefixes     = []string{"", "app", "application", "app/v"}
+	increments   = []string{"patch", "minor", "major"}
+	mainBranch   = []string{"master", "main"}
+	remoteName   = "origin"
+	versionLines = []string{"release/{major}.{minor}", "support/{major}.x"}
 )
 
 //nolint:scopelint,paralleltest
@@ -107,6 +112,16 @@ func TestShouldExtractReleaseIncrementHint(t *testing.T) {
 				{"@vergo:app/v:patch-release@ doc update", "patch"},
 			},
 		},
+		{
+			tagPrefix: "app",
+			test: []testData{
+				{"[vergo:app:pre-release] doc update", "prerelease"},
+				{"[vergo:app:premajor-release] doc update", "premajor"},
+				{"[vergo:app:preminor-release] doc update", "preminor"},
+				{"[vergo:app:prepatch-release] doc update", "prepatch"},
+				{"[vergo:app:promote-release] doc update", "promote"},
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		for _, test := range testCase.test {
@@ -228,3 +243,420 @@ func TestShouldNOTWorkWhenHeadlessCheckoutOfOtherBranch(t *testing.T) {
 		}
 	}
 }
+
+//nolint:scopelint,paralleltest
+func TestShouldParseConventionalCommit(t *testing.T) {
+	testCases := []struct {
+		message   string
+		ok        bool
+		commit    release.ConventionalCommit
+		increment string
+	}{
+		{"feat: add login", true, release.ConventionalCommit{Type: "feat", Description: "add login"}, "minor"},
+		{"fix(payments): rounding", true, release.ConventionalCommit{Type: "fix", Scope: "payments", Description: "rounding"}, "patch"},
+		{"feat(api)!: drop v1", true, release.ConventionalCommit{Type: "feat", Scope: "api", Description: "drop v1", Breaking: true}, "major"},
+		{"chore: tidy\n\nBREAKING CHANGE: config renamed", true, release.ConventionalCommit{Type: "chore", Description: "tidy", Breaking: true}, "major"},
+		{"docs: readme", true, release.ConventionalCommit{Type: "docs", Description: "readme"}, ""},
+		{"Merge pull request #1 from foo", false, release.ConventionalCommit{}, ""},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.message, func(t *testing.T) {
+			commit, ok := release.ParseConventionalCommit(testCase.message)
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.commit, commit)
+			assert.Equal(t, testCase.increment, commit.Increment())
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldExtractConventionalIncrementSinceRelease(t *testing.T) {
+	r := NewTestRepo(t)
+	DoCommitWithMessage(t, r.Repo, "some content 1", "feat!: before the release")
+	release1 := r.CreateTag("app-1.0.0", r.Head().Hash())
+
+	_, err := release.ConventionalIncrement(r.Repo, "app", release1.Hash(), release.ConventionalOptions{})
+	assert.ErrorIs(t, err, release.ErrNoIncrement)
+
+	DoCommitWithMessage(t, r.Repo, "some content 2", "fix: a bug")
+	increment, err := release.ConventionalIncrement(r.Repo, "app", release1.Hash(), release.ConventionalOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "patch", increment)
+
+	DoCommitWithMessage(t, r.Repo, "some content 3", "feat(payments): a feature")
+	DoCommitWithMessage(t, r.Repo, "some content 4", "chore: tidy up")
+	increment, err = release.ConventionalIncrement(r.Repo, "app", release1.Hash(), release.ConventionalOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "minor", increment)
+
+	increment, err = release.ConventionalIncrement(r.Repo, "app", release1.Hash(), release.ConventionalOptions{ScopeAsPrefix: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "patch", increment)
+
+	increment, err = release.ConventionalIncrement(r.Repo, "app", plumbing.ZeroHash, release.ConventionalOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "major", increment)
+}
+
+func TestShouldDefaultConventionalIncrementOnlyWithoutRelease(t *testing.T) {
+	r := NewTestRepo(t)
+	DoCommitWithMessage(t, r.Repo, "some content", "chore: tidy up")
+
+	increment, err := release.ConventionalIncrement(r.Repo, "app", plumbing.ZeroHash, release.ConventionalOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "minor", increment)
+
+	_, err = release.ConventionalIncrement(r.Repo, "app", plumbing.NewHash("0123456789abcdef0123456789abcdef01234567"),
+		release.ConventionalOptions{})
+	assert.ErrorIs(t, err, plumbing.ErrObjectNotFound)
+}
+
+func TestShouldListCommitsDownToTheMergeBase(t *testing.T) {
+	r := NewTestRepo(t)
+	base := r.Head().Hash()
+	err := r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("release/1.x"), Create: true})
+	assert.Nil(t, err)
+	DoCommitWithMessage(t, r.Repo, "some content 1", "fix: on the release branch")
+	since := r.Head().Hash()
+	err = r.Worktree().Checkout(&gogit.CheckoutOptions{Hash: base, Branch: plumbing.NewBranchReferenceName("develop"), Create: true})
+	assert.Nil(t, err)
+	DoCommitWithMessage(t, r.Repo, "some content 2", "feat: on develop")
+
+	commits, err := release.CommitsBetween(r.Repo, since, r.Head().Hash())
+	assert.Nil(t, err)
+	assert.Len(t, commits, 1)
+	assert.Equal(t, "feat: on develop", strings.TrimSpace(commits[0].Message))
+}
+
+func TestShouldExcludeCommitsOfTheReleaseMergedAgainAfterIt(t *testing.T) {
+	r := NewTestRepo(t)
+	signature := &object.Signature{Name: "vergo", Email: "vergo@example.com", When: time.Now()}
+	merge := func(message string, parents ...plumbing.Hash) {
+		_, err := r.Worktree().Commit(message, &gogit.CommitOptions{Author: signature, Committer: signature, Parents: parents})
+		assert.Nil(t, err)
+	}
+	err := r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	assert.Nil(t, err)
+	DoCommitWithMessage(t, r.Repo, "some content 1", "feat!: released in 1.0.0")
+	released := r.Head().Hash()
+	DoCommitWithMessage(t, r.Repo, "some content 2", "fix: after the release")
+	unreleased := r.Head().Hash()
+	err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")})
+	assert.Nil(t, err)
+	merge("Merge branch 'feature'", r.Head().Hash(), released)
+	release1, err := r.Repo.CreateTag("app-1.0.0", r.Head().Hash(), nil)
+	assert.Nil(t, err)
+	merge("Merge branch 'feature'", release1.Hash(), unreleased)
+
+	commits, err := release.CommitsSince(r.Repo, release1.Hash())
+	assert.Nil(t, err)
+	assert.Len(t, commits, 2)
+
+	increment, err := release.ConventionalIncrement(r.Repo, "app", release1.Hash(), release.ConventionalOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "patch", increment)
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldCreatePreReleaseWithStrategy(t *testing.T) {
+	testCases := []struct {
+		options release.PreReleaseOptions
+		version string
+	}{
+		{release.PreReleaseOptions{}, "1.3.0-SNAPSHOT"},
+		{release.PreReleaseOptions{Increment: "patch"}, "1.2.1-SNAPSHOT"},
+		{release.PreReleaseOptions{Increment: "major", Strategy: release.SnapshotStrategy}, "2.0.0-SNAPSHOT"},
+		{release.PreReleaseOptions{Strategy: release.DistanceStrategy}, "1.3.0-SNAPSHOT.2"},
+		{release.PreReleaseOptions{Strategy: release.TimestampStrategy}, "1.3.0-SNAPSHOT.20170503220343"},
+		{release.PreReleaseOptions{Strategy: release.BranchStrategy}, "1.3.0-feature-login-flow-SNAPSHOT"},
+		{release.PreReleaseOptions{Strategy: release.DistanceStrategy, Increment: "patch", WithMetadata: true}, "1.2.1-SNAPSHOT.2+{{.ShortSHA}}"},
+		{release.PreReleaseOptions{Strategy: release.TemplateStrategy, Template: "{{.Next}}-{{.Branch}}.{{.Distance}}+{{.ShortSHA}}"},
+			"1.3.0-feature-login-flow.2+{{.ShortSHA}}"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.version, func(t *testing.T) {
+			r := NewTestRepo(t)
+			r.CreateTag("app-1.2.0", r.Head().Hash())
+			err := r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature/login_flow"), Create: true})
+			assert.Nil(t, err)
+			r.DoCommit("foo")
+			r.DoCommit("bar")
+
+			options := testCase.options
+			options.TagPrefix = "app-"
+			version, err := release.PreRelease(r.Repo, options)(NewVersionT(t, "1.2.0"))
+			assert.Nil(t, err)
+			expected := strings.ReplaceAll(testCase.version, "{{.ShortSHA}}", r.Head().Hash().String()[0:7])
+			assert.Equal(t, expected, version.String())
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldFailPreReleaseWithInvalidStrategy(t *testing.T) {
+	r := NewTestRepo(t)
+	r.CreateTag("1.2.0", r.Head().Hash())
+	for _, options := range []release.PreReleaseOptions{
+		{Strategy: "unknown"},
+		{Increment: "prerelease"},
+		{Strategy: release.TemplateStrategy, Template: "{{.Next"},
+		{Strategy: release.TemplateStrategy, Template: "{{.Unknown}}"},
+		{Strategy: release.TemplateStrategy, Template: "not-a-version"},
+	} {
+		_, err := release.PreRelease(r.Repo, options)(NewVersionT(t, "1.2.0"))
+		assert.ErrorIs(t, err, release.ErrInvalidSnapshot)
+	}
+}
+
+func TestShouldParseVersionLine(t *testing.T) {
+	tests := []struct {
+		pattern, branch, line string
+	}{
+		{pattern: "release/{major}.{minor}", branch: "release/1.4", line: "1.4.x"},
+		{pattern: "release/{major}.{minor}", branch: "release/10.0", line: "10.0.x"},
+		{pattern: "support/{major}.x", branch: "support/2.x", line: "2.x"},
+		{pattern: "release/{major}.{minor}", branch: "release/1.x", line: ""},
+		{pattern: "release/{major}.{minor}", branch: "release/1.4-hotfix", line: ""},
+		{pattern: "support/{major}.x", branch: "support/2x", line: ""},
+		{pattern: "support/{major}.x", branch: "main", line: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+"-"+test.branch, func(t *testing.T) {
+			line, err := release.ParseVersionLine(test.pattern, test.branch)
+			assert.Nil(t, err)
+			if test.line == "" {
+				assert.Nil(t, line)
+			} else {
+				assert.Equal(t, test.line, line.String())
+				assert.Equal(t, test.branch, line.Branch)
+			}
+		})
+	}
+	_, err := release.ParseVersionLine("release/{minor}", "release/1")
+	assert.ErrorIs(t, err, release.ErrInvalidVersionLinePattern)
+}
+
+func TestVersionLineShouldContainVersions(t *testing.T) {
+	patchOnly := release.VersionLine{Major: 1, Minor: 4, PatchOnly: true}
+	assert.True(t, patchOnly.Contains(NewVersionT(t, "1.4.7")))
+	assert.True(t, patchOnly.Contains(NewVersionT(t, "1.4.8-rc.1")))
+	assert.False(t, patchOnly.Contains(NewVersionT(t, "1.5.0")))
+	assert.False(t, patchOnly.Contains(NewVersionT(t, "2.4.0")))
+	assert.Equal(t, "1.4.0", patchOnly.Initial().String())
+
+	major := release.VersionLine{Major: 1}
+	assert.True(t, major.Contains(NewVersionT(t, "1.5.0")))
+	assert.False(t, major.Contains(NewVersionT(t, "2.0.0")))
+	assert.Equal(t, "1.0.0", major.Initial().String())
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldFindCurrentVersionLine(t *testing.T) {
+	r := NewTestRepo(t)
+	line, err := release.CurrentVersionLine(r.Repo, remoteName, versionLines)
+	assert.Nil(t, err)
+	assert.Nil(t, line)
+
+	err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("release/1.4"), Create: true})
+	assert.Nil(t, err)
+	r.DoCommit("foo")
+	line, err = release.CurrentVersionLine(r.Repo, remoteName, versionLines)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.4.x", line.String())
+
+	line, err = release.CurrentVersionLine(r.Repo, remoteName, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, line)
+
+	err = r.Worktree().Checkout(&gogit.CheckoutOptions{Hash: r.Head().Hash()})
+	assert.Nil(t, err)
+	assert.Equal(t, plumbing.HEAD.String(), r.Head().Name().Short())
+	line, err = release.CurrentVersionLine(r.Repo, remoteName, versionLines)
+	assert.Nil(t, err)
+	assert.Equal(t, "release/1.4", line.Branch)
+	line, err = release.CurrentVersionLine(r.Repo, remoteName, []string{"support/{major}.x"})
+	assert.Nil(t, err)
+	assert.Nil(t, line)
+}
+
+func TestShouldMatchBranchPatterns(t *testing.T) {
+	tests := []struct {
+		pattern, branch string
+		match           bool
+	}{
+		{pattern: "main", branch: "main", match: true},
+		{pattern: "main", branch: "maintenance", match: false},
+		{pattern: "release/*", branch: "release/1.4", match: true},
+		{pattern: "release/*", branch: "release/1.4/fix", match: false},
+		{pattern: "release/*", branch: "releases/1.4", match: false},
+		{pattern: "hotfix-?", branch: "hotfix-1", match: true},
+		{pattern: "re:hotfix-.*", branch: "hotfix-payments", match: true},
+		{pattern: "re:hotfix-.*", branch: "my-hotfix-payments", match: false},
+		{pattern: "re:main|master", branch: "master", match: true},
+		{pattern: "re:main|master", branch: "mastery", match: false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+"-"+test.branch, func(t *testing.T) {
+			match, err := release.MatchBranch([]string{test.pattern}, test.branch)
+			assert.Nil(t, err)
+			assert.Equal(t, test.match, match)
+		})
+	}
+	_, err := release.MatchBranch([]string{"re:hotfix-("}, "hotfix-1")
+	assert.ErrorIs(t, err, release.ErrInvalidBranchPattern)
+	_, err = release.MatchBranch([]string{"release/["}, "release/1")
+	assert.ErrorIs(t, err, release.ErrInvalidBranchPattern)
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldValidateHEADWithBranchPatterns(t *testing.T) {
+	for _, versionedBranches := range [][]string{{"main", "release/*"}, {"main", "re:release/[0-9.]+"}} {
+		t.Run(strings.Join(versionedBranches, ","), func(t *testing.T) {
+			r := NewTestRepo(t)
+			err := r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("release/1.4"), Create: true})
+			assert.Nil(t, err)
+			r.DoCommit("foo")
+			assert.Nil(t, release.ValidateHEAD(r.Repo, remoteName, versionedBranches))
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Hash: r.Head().Hash()})
+			assert.Nil(t, err)
+			assert.Equal(t, plumbing.HEAD.String(), r.Head().Name().Short())
+			assert.Nil(t, release.ValidateHEAD(r.Repo, remoteName, versionedBranches))
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("apple"), Create: true})
+			assert.Nil(t, err)
+			r.DoCommit("bar")
+			err = release.ValidateHEAD(r.Repo, remoteName, versionedBranches)
+			assert.Regexp(t, "branch apple is not in versioned branches list", err)
+
+			err = r.Worktree().Checkout(&gogit.CheckoutOptions{Hash: r.Head().Hash()})
+			assert.Nil(t, err)
+			err = release.ValidateHEAD(r.Repo, remoteName, versionedBranches)
+			assert.Regexp(t, "is not on a versioned branch", err)
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldValidateHeadlessCheckoutWithRemoteBranchPattern(t *testing.T) {
+	r := NewTestRepo(t)
+	_, err := r.Repo.CreateRemote(&config.RemoteConfig{Name: remoteName, URLs: []string{"https://example.com/repo.git"}})
+	assert.Nil(t, err)
+	r.DoCommit("foo")
+	remoteBranch := plumbing.NewHashReference(plumbing.NewRemoteReferenceName(remoteName, "release/1.4"), r.Head().Hash())
+	assert.Nil(t, r.Repo.Storer.SetReference(remoteBranch))
+	err = r.Worktree().Checkout(&gogit.CheckoutOptions{Hash: r.Head().Hash()})
+	assert.Nil(t, err)
+
+	assert.Nil(t, release.ValidateHEAD(r.Repo, remoteName, []string{"release/*"}))
+	err = release.ValidateHEAD(r.Repo, remoteName, []string{"hotfix/*"})
+	assert.Regexp(t, "is not on a versioned branch: hotfix/\\*", err)
+
+	localBranch := plumbing.NewHashReference(plumbing.NewBranchReferenceName("support/1.x"), r.Head().Hash())
+	assert.Nil(t, r.Repo.Storer.SetReference(localBranch))
+	assert.Nil(t, release.ValidateHEAD(r.Repo, remoteName, []string{"support/*"}))
+	assert.Nil(t, release.ValidateHEAD(r.Repo, remoteName, []string{"support/1.x"}))
+}
+
+func TestShouldParseChannels(t *testing.T) {
+	channels, err := release.ParseChannels([]string{"develop=beta", "feature/*={branch}", "re:hotfix-.*=fix-{branch}", "main={branch}"})
+	assert.Nil(t, err)
+	assert.Len(t, channels, 4)
+	tests := []struct {
+		channel    int
+		branch     string
+		identifier string
+	}{
+		{channel: 0, branch: "develop", identifier: "beta"},
+		{channel: 1, branch: "feature/login-flow", identifier: "login-flow"},
+		{channel: 1, branch: "feature/Login_Flow", identifier: "Login-Flow"},
+		{channel: 2, branch: "hotfix-payments", identifier: "fix-payments"},
+		{channel: 3, branch: "main", identifier: "main"},
+	}
+	for _, test := range tests {
+		t.Run(test.branch, func(t *testing.T) {
+			assert.True(t, channels[test.channel].Pattern.Match(test.branch))
+			identifier, err := channels[test.channel].IdentifierFor(test.branch)
+			assert.Nil(t, err)
+			assert.Equal(t, test.identifier, identifier)
+		})
+	}
+	for _, invalid := range []string{"develop", "develop=", "=beta", "re:(=beta"} {
+		_, err := release.ParseChannels([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldFindCurrentChannel(t *testing.T) {
+	channels := []string{"develop=beta", "feature/*={branch}"}
+	r := NewTestRepo(t)
+	identifier, err := release.CurrentChannel(r.Repo, remoteName, channels)
+	assert.Nil(t, err)
+	assert.Equal(t, "", identifier)
+
+	err = r.Worktree().Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature/login-flow"), Create: true})
+	assert.Nil(t, err)
+	r.DoCommit("foo")
+	identifier, err = release.CurrentChannel(r.Repo, remoteName, channels)
+	assert.Nil(t, err)
+	assert.Equal(t, "login-flow", identifier)
+
+	err = r.Worktree().Checkout(&gogit.CheckoutOptions{Hash: r.Head().Hash()})
+	assert.Nil(t, err)
+	identifier, err = release.CurrentChannel(r.Repo, remoteName, channels)
+	assert.Nil(t, err)
+	assert.Equal(t, "login-flow", identifier)
+}
+
+func TestPathFilterShouldMatchGlobs(t *testing.T) {
+	tests := []struct {
+		filter release.PathFilter
+		file   string
+		match  bool
+	}{
+		{filter: release.PathFilter{}, file: "main.go", match: true},
+		{filter: release.PathFilter{Paths: []string{"services/api"}}, file: "services/api/main.go", match: true},
+		{filter: release.PathFilter{Paths: []string{"services/api/"}}, file: "services/api/internal/db.go", match: true},
+		{filter: release.PathFilter{Paths: []string{"services/api"}}, file: "services/api-gateway/main.go", match: false},
+		{filter: release.PathFilter{Paths: []string{"services/*/main.go"}}, file: "services/api/main.go", match: true},
+		{filter: release.PathFilter{Paths: []string{"services/*.go"}}, file: "services/api/main.go", match: false},
+		{filter: release.PathFilter{Paths: []string{"**/*.go"}}, file: "main.go", match: true},
+		{filter: release.PathFilter{Paths: []string{"services/**/db.go"}}, file: "services/api/internal/db.go", match: true},
+		{filter: release.PathFilter{Paths: []string{"./libs/common"}}, file: "libs/common/a.go", match: true},
+		{filter: release.PathFilter{Paths: []string{"services/api"}, IgnorePaths: []string{"**/*.md"}}, file: "services/api/README.md", match: false},
+		{filter: release.PathFilter{Paths: []string{"services/api"}, IgnorePaths: []string{"services/api/docs"}}, file: "services/api/docs/a.txt", match: false},
+		{filter: release.PathFilter{IgnorePaths: []string{"*_test.go"}}, file: "main_test.go", match: false},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v-%s", test.filter, test.file), func(t *testing.T) {
+			match, err := test.filter.Match(test.file)
+			assert.Nil(t, err)
+			assert.Equal(t, test.match, match)
+		})
+	}
+}
+
+//nolint:scopelint,paralleltest
+func TestShouldDetectChangedPaths(t *testing.T) {
+	r := NewTestRepo(t)
+	api := release.PathFilter{Paths: []string{"services/api"}, IgnorePaths: []string{"**/*.md"}}
+	changed, err := release.Changed(r.Repo, plumbing.ZeroHash, api)
+	assert.Nil(t, err)
+	assert.Empty(t, changed)
+
+	tag := r.CreateTag("app-0.1.0", r.Head().Hash())
+	r.DoCommit("services/web/main.go")
+	r.DoCommit("services/api/README.md")
+	_, err = release.Changed(r.Repo, tag.Hash(), api)
+	assert.ErrorIs(t, err, release.ErrNoChanges)
+
+	r.DoCommit("services/api/main.go")
+	changed, err = release.Changed(r.Repo, tag.Hash(), api)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"services/api/main.go"}, changed)
+
+	files, err := release.ChangedFiles(r.Repo, tag.Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"services/api/README.md", "services/api/main.go", "services/web/main.go"}, files)
+}
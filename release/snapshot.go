@@ -0,0 +1,141 @@
+package release
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	SnapshotStrategy  = "snapshot"
+	DistanceStrategy  = "distance"
+	TimestampStrategy = "timestamp"
+	BranchStrategy    = "branch"
+	TemplateStrategy  = "template"
+
+	snapshotIdentifier = "SNAPSHOT"
+	timestampLayout    = "20060102150405"
+)
+
+var (
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+	// SnapshotStrategies are the supported PreReleaseOptions.Strategy values
+	SnapshotStrategies = []string{SnapshotStrategy, DistanceStrategy, TimestampStrategy, BranchStrategy, TemplateStrategy}
+
+	invalidIdentifierChars = regexp.MustCompile(`[^0-9A-Za-z-]+`)
+)
+
+// SnapshotData is available to the snapshot template e.g. {{.Next}}-{{.Branch}}.{{.Distance}}+{{.ShortSHA}}
+type SnapshotData struct {
+	// Version is the latest release
+	Version string
+	// Next is the latest release incremented by PreReleaseOptions.Increment
+	Next string
+	// Branch is the current branch sanitised to a pre-release identifier
+	Branch string
+	// Distance is the number of commits since the latest release
+	Distance int
+	// SHA and ShortSHA are the hash of HEAD
+	SHA, ShortSHA string
+	// Timestamp is the committer time of HEAD in UTC, formatted as yyyyMMddHHmmss
+	Timestamp string
+}
+
+func snapshotData(repo *gogit.Repository, version *semver.Version, options PreReleaseOptions) (SnapshotData, error) {
+	next, err := nextSnapshotVersion(version, options.Increment)
+	if err != nil {
+		return SnapshotData{}, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return SnapshotData{}, err
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return SnapshotData{}, err
+	}
+	data := SnapshotData{
+		Version:   version.String(),
+		Next:      next.String(),
+		Branch:    SanitiseIdentifier(head.Name().Short()),
+		SHA:       head.Hash().String(),
+		ShortSHA:  head.Hash().String()[0:7],
+		Timestamp: headCommit.Committer.When.UTC().Format(timestampLayout),
+	}
+	if head.Name() == plumbing.HEAD || data.Branch == "" {
+		data.Branch = "detached"
+	}
+	if options.Strategy == DistanceStrategy || options.Strategy == TemplateStrategy {
+		// the distance of an unreleased version is counted from the first commit
+		since := plumbing.ZeroHash
+		tag, err := repo.Tag(options.TagPrefix + version.Original())
+		switch {
+		case errors.Is(err, gogit.ErrTagNotFound) && version.Equal(semver.MustParse("0.0.0")):
+		case err != nil:
+			return SnapshotData{}, fmt.Errorf("%w : %s", err, options.TagPrefix+version.Original())
+		default:
+			since = tag.Hash()
+		}
+		commits, err := CommitsBetween(repo, since, head.Hash())
+		if err != nil {
+			return SnapshotData{}, err
+		}
+		data.Distance = len(commits)
+	}
+	return data, nil
+}
+
+func nextSnapshotVersion(version *semver.Version, increment string) (semver.Version, error) {
+	switch increment {
+	case "patch":
+		return version.IncPatch(), nil
+	case "minor", "":
+		return version.IncMinor(), nil
+	case "major":
+		return version.IncMajor(), nil
+	default:
+		return semver.Version{}, fmt.Errorf("%w : unknown increment %s", ErrInvalidSnapshot, increment)
+	}
+}
+
+func snapshot(data SnapshotData, options PreReleaseOptions) (semver.Version, error) {
+	next := semver.MustParse(data.Next)
+	switch options.Strategy {
+	case SnapshotStrategy, "":
+		return next.SetPrerelease(snapshotIdentifier)
+	case DistanceStrategy:
+		return next.SetPrerelease(fmt.Sprintf("%s.%d", snapshotIdentifier, data.Distance))
+	case TimestampStrategy:
+		return next.SetPrerelease(snapshotIdentifier + "." + data.Timestamp)
+	case BranchStrategy:
+		return next.SetPrerelease(data.Branch + "-" + snapshotIdentifier)
+	case TemplateStrategy:
+		tmpl, err := template.New("snapshot").Option("missingkey=error").Parse(options.Template)
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("%w : %s", ErrInvalidSnapshot, err)
+		}
+		var version bytes.Buffer
+		if err := tmpl.Execute(&version, data); err != nil {
+			return semver.Version{}, fmt.Errorf("%w : %s", ErrInvalidSnapshot, err)
+		}
+		rendered, err := semver.StrictNewVersion(strings.TrimSpace(version.String()))
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("%w : %s %s", ErrInvalidSnapshot, version.String(), err)
+		}
+		return *rendered, nil
+	default:
+		return semver.Version{}, fmt.Errorf("%w : unknown strategy %s", ErrInvalidSnapshot, options.Strategy)
+	}
+}
+
+// SanitiseIdentifier turns the string into a valid pre-release identifier e.g. feature/login_flow -> feature-login-flow
+func SanitiseIdentifier(s string) string {
+	return strings.Trim(invalidIdentifierChars.ReplaceAllString(s, "-"), "-")
+}